@@ -22,11 +22,19 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"encoding/csv"
+	"context"
+	"errors"
 	"fmt"
+	"github.com/dstockto/csv-chef/csv"
 	"github.com/dstockto/csv-chef/recipe"
 	"github.com/google/martian/log"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
 
 	"github.com/spf13/cobra"
 )
@@ -34,10 +42,29 @@ import (
 var (
 	transformLines int
 	disableHeader  bool
+	hideProgress   bool
 	forceOverwrite bool
-	inputFile      string
+	inputFiles     []string
+	alignHeaders   bool
 	outputFile     string
 	recipeFile     string
+	workers        int
+	onError        string
+	rejectsFile    string
+	outputFormat   string
+	inFormat       string
+	inLayout       string
+	outLayout      string
+	overflow       string
+	inputEncoding  string
+	outputEncoding string
+	splitBy        string
+	maxRows        int
+	sqlTable       string
+	sqlDialect     string
+	sqlBatchSize   int
+	htmlPage       bool
+	recipeParams   []string
 )
 
 // bakeCmd represents the bake command
@@ -45,16 +72,23 @@ var bakeCmd = &cobra.Command{
 	Use:   "bake -i /path/to/input.csv -o /path/to/output.csv -r /path/to/recipe",
 	Short: "Bake uses a recipe to transform a CSV file",
 	Long: `Using a recipe file, bake allows you to transform an input file to another
-output file where each output line can be manipulated according to the rules you've 
-created in the recipe file. Please see the README for how to make recipes. The -f flag can be used to
-overwrite the output file if it exists. The -d flag will disable processing of headers with header rules 
-for the first line of the file. The -n flag can tag a number representing the maximum number of lines
-to process from the input file. This can be helpful if you are testing a recipe and the input file is large.'`,
+output file where each output line can be manipulated according to the rules you've
+created in the recipe file. Please see the README for how to make recipes.
+
+The input can be CSV, JSON or fixed-width, from one or more files or stdin, and the
+output can be CSV, JSON, SQL, Markdown, HTML or fixed-width, to a file, several files or
+stdout. Rows that fail can stop the bake, be skipped or be written to a rejects file.
+Each flag is described below, and the README has the details of each format.`,
+	Example: `  csv-chef bake -i voters.csv -o output.csv -r recipe.txt
+  csv-chef bake -i voters.csv -o - -r recipe.txt -n 10 --format markdown
+  csv-chef bake -i 'data/*.csv' -o output.csv.gz -r recipe.txt -w 4 --rejects rejects.csv
+  csv-chef bake -i voters.ndjson -o voters.sql -r recipe.txt --format sql --table voters --sql-dialect mysql
+  csv-chef bake -i voters.csv -o 'out/{value}.csv' -r recipe.txt --split-by '$state' --set batch=2026Q3`,
 	Run: runBake,
 }
 
 func runBake(cmd *cobra.Command, args []string) {
-	if inputFile == "" {
+	if len(inputFiles) == 0 {
 		log.Errorf("Please specify an input file path with -i or --in")
 		os.Exit(1)
 	}
@@ -67,52 +101,462 @@ func runBake(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	in, err := os.Open(inputFile)
-	if err != nil {
-		log.Errorf("Error opening input file: %v", err)
+	outputFormat = strings.ToLower(outputFormat)
+	if outputFormat == "md" {
+		outputFormat = "markdown"
+	}
+	if outLayout != "" && !cmd.Flags().Changed("format") {
+		outputFormat = "fixed"
+	}
+	switch outputFormat {
+	case "csv", "json", "ndjson", "markdown", "html":
+	case "sql":
+		if sqlTable == "" {
+			log.Errorf("Please specify the table to create with --table")
+			os.Exit(1)
+		}
+		if _, err := csv.ParseSQLDialect(sqlDialect); err != nil {
+			log.Errorf("%v", err)
+			os.Exit(1)
+		}
+	case "fixed":
+		if outLayout == "" {
+			log.Errorf("Please specify the layout of fixed-width output with --out-layout")
+			os.Exit(1)
+		}
+	default:
+		log.Errorf("Unrecognized output format '%s', expected csv, json, ndjson, sql, markdown, html or fixed", outputFormat)
+		os.Exit(1)
+	}
+	if overflow != "truncate" && overflow != "reject" {
+		log.Errorf("Unrecognized overflow policy '%s', expected truncate or reject", overflow)
+		os.Exit(1)
+	}
+	splitting := splitBy != "" || maxRows > 0
+	if splitting && outputFile == csv.StdPath {
+		log.Errorf("Split output is written to files, please give a file name like out_{value}.csv with -o")
 		os.Exit(1)
 	}
-	defer in.Close()
 
-	// ensure output doesn't exist, or force is specified
-	if _, err := os.Stat(outputFile); err == nil && !forceOverwrite {
-		log.Errorf("Output file already exists: %s", output)
-		os.Exit(5)
+	inputPaths, err := expandInputs(inputFiles)
+	if err != nil {
+		log.Errorf("%v", err)
+		os.Exit(1)
+	}
+	for _, path := range inputPaths {
+		format, err := inputFormat(path, inFormat, inLayout)
+		if err != nil {
+			log.Errorf("%v", err)
+			os.Exit(1)
+		}
+		if format != "csv" && disableHeader {
+			log.Errorf("--no-header can't be used with %s input, the field names are the header", format)
+			os.Exit(1)
+		}
+	}
+	if alignHeaders && disableHeader {
+		log.Errorf("--align-headers needs the headers, it can't be used with --no-header")
+		os.Exit(1)
 	}
 
-	out, err := os.Create(outputFile)
+	// a rejects file means rejecting rows, unless --on-error says otherwise
+	if rejectsFile != "" && !cmd.Flags().Changed("on-error") {
+		onError = "reject"
+	}
+	errorMode, err := recipe.ParseErrorMode(onError)
 	if err != nil {
-		log.Errorf("Error creating output file: %v", err)
-		os.Exit(6)
+		log.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if errorMode != recipe.Reject && rejectsFile != "" {
+		log.Errorf("--rejects can only be used with --on-error=reject")
+		os.Exit(1)
+	}
+	if errorMode == recipe.Reject && rejectsFile == "" {
+		log.Errorf("Please specify a rejects file path with --rejects when using --on-error=reject")
+		os.Exit(1)
+	}
+
+	if outputFile == csv.StdPath && rejectsFile == csv.StdPath {
+		log.Errorf("The output and the rejects can't both be written to stdout")
+		os.Exit(1)
 	}
-	defer out.Close()
 
-	recipeFile, err := os.Open(recipeFile)
+	if _, err := csv.NewDecoder(strings.NewReader(""), inputEncoding); err != nil {
+		log.Errorf("Invalid input encoding: %v", err)
+		os.Exit(1)
+	}
+	inputSize, err := totalInputSize(inputPaths)
 	if err != nil {
+		log.Errorf("Error opening input file: %v", err)
+		os.Exit(1)
+	}
+
+	// ensure output doesn't exist, or force is specified. Split output files are created as they are needed.
+	var out *csv.Output
+	if splitting {
+		if _, err := csv.NewEncoder(io.Discard, outputEncoding); err != nil {
+			log.Errorf("Invalid output encoding: %v", err)
+			os.Exit(1)
+		}
+	} else {
+		out, err = csv.CreateOutput(outputFile, forceOverwrite)
+		if errors.Is(err, os.ErrExist) {
+			log.Errorf("Output file already exists: %s", outputFile)
+			os.Exit(5)
+		}
+		if err != nil {
+			log.Errorf("Error creating output file: %v", err)
+			os.Exit(6)
+		}
+		defer out.Close()
+		if err := out.Encode(outputEncoding); err != nil {
+			log.Errorf("Invalid output encoding: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	options := recipe.ExecuteOptions{Workers: workers, OnError: errorMode}
+	var rejects *csv.Output
+	if errorMode == recipe.Reject {
+		rejects, err = csv.CreateOutput(rejectsFile, forceOverwrite)
+		if errors.Is(err, os.ErrExist) {
+			log.Errorf("Rejects file already exists: %s", rejectsFile)
+			os.Exit(5)
+		}
+		if err != nil {
+			log.Errorf("Error creating rejects file: %v", err)
+			os.Exit(6)
+		}
+		defer rejects.Close()
+	}
+
+	if _, err := os.Stat(recipeFile); err != nil {
 		log.Errorf("Unable to open recipe file: %v", err)
 		os.Exit(6)
 	}
-	defer recipeFile.Close()
 
-	transformer, err := recipe.Parse(recipeFile)
+	params, err := paramsFromFlags(recipeParams)
 	if err != nil {
-		log.Errorf("Error processing your recipe: %v", err)
+		log.Errorf("Invalid parameter: %v", err)
+		os.Exit(1)
+	}
+	transformer, err := recipe.ParseFileWithOptions(recipeFile, recipe.ParseOptions{Params: params})
+	if err != nil {
+		if !printParseErrors(os.Stderr, recipeFile, err) {
+			log.Errorf("Error processing your recipe: %v", err)
+		}
 		os.Exit(7)
 	}
 
+	inputDialect, err := dialectFromFlags(cmd, transformer.InputDialect, inputDialectFlags)
+	if err != nil {
+		log.Errorf("Invalid input dialect: %v", err)
+		os.Exit(1)
+	}
+	outputDialect, err := dialectFromFlags(cmd, transformer.OutputDialect, outputDialectFlags)
+	if err != nil {
+		log.Errorf("Invalid output dialect: %v", err)
+		os.Exit(1)
+	}
+	// lookup files are read like the input
+	transformer.InputDialect = inputDialect
+	transformer.InputEncoding = inputEncoding
+	// try out the readers before baking, so problems with them aren't found part way through the files
+	for _, path := range inputPaths {
+		format, _ := inputFormat(path, inFormat, inLayout)
+		if _, err := newInputReader(strings.NewReader(""), format, inputDialect, transformer.Fields, inLayout); err != nil {
+			log.Errorf("Error reading input: %v", err)
+			os.Exit(1)
+		}
+	}
+	// each file is opened when the one before it has been read
+	open := func(path string) (csv.RowReader, func() error, error) {
+		in, err := csv.OpenInput(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		format, _ := inputFormat(path, inFormat, inLayout)
+		var reader recipe.RowReader
+		err = in.Decode(inputEncoding)
+		if err == nil {
+			reader, err = newInputReader(in, format, inputDialect, transformer.Fields, inLayout)
+		}
+		if err != nil {
+			_ = in.Close()
+			return nil, nil, err
+		}
+		return reader, in.Close, nil
+	}
+	reader := csv.NewMultiReader(inputPaths, open, !disableHeader, alignHeaders)
+	var writer recipe.RowWriter
+	var finish func() error
+	var split *recipe.SplitWriter
+	if splitting {
+		// try out the writer before baking, so a bad layout isn't found once the first file is created. It also checks
+		// the rows for a new file before the file is created.
+		check, _, err := newBakeWriter(io.Discard, outputDialect, transformer.Types)
+		if err != nil {
+			log.Errorf("Error creating output: %v", err)
+			os.Exit(1)
+		}
+		checker, _ := check.(recipe.RowChecker)
+		split, err = transformer.NewSplitWriter(splitBy, maxRows, !disableHeader, createSplitFile(outputDialect, transformer.Types), checker)
+		if err != nil {
+			log.Errorf("Unable to split the output: %v", err)
+			os.Exit(1)
+		}
+		writer, finish = split, split.Close
+	} else {
+		// dialects were validated, so creating the writers can't fail
+		writer, finish, err = newBakeWriter(out, outputDialect, transformer.Types)
+		if err != nil {
+			log.Errorf("Error creating output: %v", err)
+			os.Exit(1)
+		}
+	}
+	if rejects != nil {
+		// rejected rows are written the way they were read, so they can be fixed and baked again
+		options.Rejects, _ = csv.NewWriter(rejects, csv.Dialect{Delimiter: inputDialect.Delimiter, Quote: inputDialect.Quote})
+	}
+
 	// Don't count the header
 	if transformLines > 0 && !disableHeader {
 		transformLines++
 	}
 
-	result, err := transformer.Execute(csv.NewReader(in), csv.NewWriter(out), !disableHeader, transformLines)
+	// the summary and progress go to stderr when stdout carries the output
+	status := os.Stdout
+	if outputFile == csv.StdPath || rejectsFile == csv.StdPath {
+		status = os.Stderr
+	}
+
+	var bar *progressBar
+	if !hideProgress && isTerminal(status) {
+		bar = newProgressBar(status, inputSize)
+		options.Progress = bar.Update
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := transformer.ExecuteContext(ctx, reader, writer, !disableHeader, transformLines, options)
+	if bar != nil {
+		bar.Clear()
+	}
+	if err == nil {
+		err = finish()
+	}
+	if err == nil && out != nil {
+		err = out.Close()
+	}
+	if err == nil && rejects != nil {
+		err = rejects.Close()
+	}
+	if errors.Is(err, os.ErrExist) {
+		log.Errorf("Output file already exists: %v", err)
+		os.Exit(5)
+	}
 	if err != nil {
 		log.Errorf("Error during baking: %v", err)
 		os.Exit(8)
 	}
 
-	fmt.Printf("Baking complete. Your output is here: %s\n\n", outputFile)
-	fmt.Printf("Processed %d header lines and %d input lines\n", result.HeaderLines, result.Lines)
+	where := outputFile
+	if where == csv.StdPath {
+		where = "stdout"
+	}
+	if split != nil {
+		fmt.Fprintf(status, "Baking complete. Your output is in %d files:\n", len(result.Files))
+		for _, f := range result.Files {
+			fmt.Fprintf(status, "  %s: %d lines\n", splitFileName(outputFile, f.Key, f.Part), f.Rows)
+		}
+		fmt.Fprintln(status)
+	} else {
+		fmt.Fprintf(status, "Baking complete. Your output is here: %s\n\n", where)
+	}
+	if len(inputPaths) > 1 {
+		fmt.Fprintf(status, "Read %d input files\n", len(inputPaths))
+	}
+	fmt.Fprintf(status, "Processed %d header lines and %d input lines\n", result.HeaderLines, result.Lines)
+	if result.Skipped > 0 {
+		fmt.Fprintf(status, "Kept %d rows and skipped %d lines\n", result.Kept, result.Skipped)
+	}
+	if transformer.IsGrouped() {
+		fmt.Fprintf(status, "Wrote %d groups\n", result.Groups)
+	}
+	if result.Rejected > 0 && errorMode == recipe.Reject {
+		fmt.Fprintf(status, "Rejected %d lines, see %s\n", result.Rejected, rejectsFile)
+	} else if result.Rejected > 0 {
+		fmt.Fprintf(status, "Skipped %d lines with errors\n", result.Rejected)
+	}
+}
+
+// expandInputs turns the -i flags into the list of input files, expanding any glob patterns. A pattern that doesn't
+// match any files is an error. Stdin can only be read on its own.
+func expandInputs(patterns []string) ([]string, error) {
+	var paths []string
+	for _, pattern := range patterns {
+		if pattern == csv.StdPath {
+			if len(patterns) > 1 {
+				return nil, fmt.Errorf("stdin can't be read along with other input files")
+			}
+			return patterns, nil
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input pattern %s: %v", pattern, err)
+		}
+		if len(matches) == 0 {
+			if _, err := os.Stat(pattern); err != nil {
+				return nil, fmt.Errorf("no input files match %s", pattern)
+			}
+			matches = []string{pattern}
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// totalInputSize returns the combined size of the input files for the progress bar, or 0 when it can't be known
+// because the input is stdin, gzipped or turned into UTF-8 from another encoding. It also makes sure every file can be
+// opened before baking starts.
+func totalInputSize(paths []string) (int64, error) {
+	if len(paths) == 1 && paths[0] == csv.StdPath {
+		return 0, nil
+	}
+	var total int64
+	known := true
+	for _, path := range paths {
+		in, err := csv.OpenInput(path)
+		if err != nil {
+			return 0, err
+		}
+		if err := in.Decode(inputEncoding); err != nil {
+			_ = in.Close()
+			return 0, err
+		}
+		if in.Size == 0 {
+			known = false
+		}
+		total += in.Size
+		if err := in.Close(); err != nil {
+			return 0, err
+		}
+	}
+	if !known {
+		return 0, nil
+	}
+	return total, nil
+}
+
+// createSplitFile returns the function that creates each file of split output, named from the -o template by
+// splitFileName.
+func createSplitFile(dialect csv.Dialect, types map[int]csv.ValueType) recipe.CreateFunc {
+	keys := make(map[string]string) // the key each file name was made from
+	return func(key string, part int) (recipe.RowWriter, func() error, error) {
+		name := splitFileName(outputFile, key, part)
+		if other, ok := keys[name]; ok && other != key {
+			return nil, nil, fmt.Errorf("the values '%s' and '%s' would both be written to %s", other, key, name)
+		}
+		keys[name] = key
+
+		out, err := csv.CreateOutput(name, forceOverwrite)
+		if err != nil {
+			return nil, nil, err
+		}
+		var writer recipe.RowWriter
+		var finish func() error
+		err = out.Encode(outputEncoding)
+		if err == nil {
+			writer, finish, err = newBakeWriter(out, dialect, types)
+		}
+		if err != nil {
+			_ = out.Close()
+			return nil, nil, err
+		}
+		return writer, func() error {
+			err := finish()
+			if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+			return err
+		}, nil
+	}
+}
+
+// splitFileName makes the name of a file of split output from the -o template, replacing {value} with the value the
+// rows were split by and {part} with the number of the file for that value. When the template doesn't have them,
+// they are added before the extension, like out_CO_2.csv.
+func splitFileName(template string, key string, part int) string {
+	ext := filepath.Ext(strings.TrimSuffix(template, ".gz"))
+	if strings.HasSuffix(template, ".gz") {
+		ext += ".gz"
+	}
+	if splitBy != "" && !strings.Contains(template, "{value}") {
+		template = strings.TrimSuffix(template, ext) + "_{value}" + ext
+	}
+	if maxRows > 0 && !strings.Contains(template, "{part}") {
+		template = strings.TrimSuffix(template, ext) + "_{part}" + ext
+	}
+	return strings.NewReplacer("{value}", safeFileName(key), "{part}", strconv.Itoa(part)).Replace(template)
+}
+
+// safeFileName makes a value safe to use as part of a file name.
+func safeFileName(value string) string {
+	if value == "" {
+		return "empty"
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' || r == ' ' {
+			return r
+		}
+		return '_'
+	}, value)
+}
+
+// newBakeWriter returns the writer for the output format, and a function to call once baking is done that finishes
+// the output and returns any error from writing it.
+func newBakeWriter(out io.Writer, dialect csv.Dialect, types map[int]csv.ValueType) (recipe.RowWriter, func() error, error) {
+	switch outputFormat {
+	case "json", "ndjson":
+		writer := csv.NewJSONWriter(out, csv.JSONOptions{NDJSON: outputFormat == "ndjson", NoHeader: disableHeader, Types: types})
+		return writer, writer.Close, nil
+	case "sql":
+		dialect, err := csv.ParseSQLDialect(sqlDialect)
+		if err != nil {
+			return nil, nil, err
+		}
+		options := csv.SQLOptions{Table: sqlTable, Dialect: dialect, BatchSize: sqlBatchSize, NoHeader: disableHeader, Types: types}
+		writer := csv.NewSQLWriter(out, options)
+		return writer, writer.Close, nil
+	case "markdown":
+		writer := csv.NewMarkdownWriter(out, csv.TableOptions{NoHeader: disableHeader, Types: types})
+		return writer, writer.Close, nil
+	case "html":
+		options := csv.TableOptions{NoHeader: disableHeader, Types: types, Page: htmlPage, Title: pageTitle()}
+		writer := csv.NewHTMLWriter(out, options)
+		return writer, writer.Close, nil
+	case "fixed":
+		layout, err := csv.LoadLayout(outLayout)
+		if err != nil {
+			return nil, nil, err
+		}
+		options := csv.FixedWidthOptions{NoHeader: disableHeader, Truncate: overflow == "truncate", CRLF: dialect.CRLF}
+		writer := csv.NewFixedWidthWriter(out, layout, options)
+		return writer, writer.Error, nil
+	}
+	writer, _ := csv.NewWriter(out, dialect)
+	return writer, writer.Error, nil
+}
+
+// pageTitle returns the title of a standalone HTML page, the name of the output file without its extension.
+func pageTitle() string {
+	if outputFile == csv.StdPath {
+		return strings.TrimSuffix(filepath.Base(recipeFile), filepath.Ext(recipeFile))
+	}
+	name := filepath.Base(strings.TrimSuffix(outputFile, ".gz"))
+	return strings.TrimSuffix(name, filepath.Ext(name))
 }
 
 func init() {
@@ -123,12 +567,33 @@ func init() {
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
 	// bakeCmd.PersistentFlags().String("foo", "", "A help for foo")
-	bakeCmd.Flags().IntVarP(&transformLines, "lines", "n", -1, "-n 100")
-	bakeCmd.Flags().BoolVarP(&disableHeader, "no-header", "d", false, "--no-header")
-	bakeCmd.Flags().BoolVarP(&forceOverwrite, "force", "f", false, "--force (force output)")
-	bakeCmd.Flags().StringVarP(&inputFile, "in", "i", "", "-i /path/to/input.csv")
-	bakeCmd.Flags().StringVarP(&outputFile, "out", "o", "", "-o /path/to/output.csv")
+	bakeCmd.Flags().IntVarP(&transformLines, "lines", "n", -1, "-n 100 (only bake this many lines, handy for trying a recipe on a large file)")
+	bakeCmd.Flags().BoolVarP(&disableHeader, "no-header", "d", false, "--no-header (the first line is data, header recipes aren't used)")
+	bakeCmd.Flags().BoolVar(&hideProgress, "no-progress", false, "--no-progress (don't show a progress bar)")
+	bakeCmd.Flags().BoolVarP(&forceOverwrite, "force", "f", false, "--force (overwrite the output file if it exists)")
+	bakeCmd.Flags().StringArrayVarP(&inputFiles, "in", "i", nil, "-i /path/to/input.csv (- for stdin, repeat or use a pattern like 'data/*.csv' for more than one file)")
+	bakeCmd.Flags().BoolVar(&alignHeaders, "align-headers", false, "--align-headers (match the columns of each input file to the first by header name)")
+	bakeCmd.Flags().StringVarP(&outputFile, "out", "o", "", "-o /path/to/output.csv (- for stdout)")
 	bakeCmd.Flags().StringVarP(&recipeFile, "recipe", "r", "", "-r /path/to/recipe.txt")
+	bakeCmd.Flags().StringArrayVar(&recipeParams, "set", nil, "--set batch=2026Q3 (value of a recipe parameter, read as $param.batch, repeat for more than one)")
+	bakeCmd.Flags().IntVarP(&workers, "workers", "w", 1, "-w 4 (number of rows to transform at the same time)")
+	bakeCmd.Flags().StringVar(&onError, "on-error", "fail", "--on-error=skip (fail, skip or reject rows with errors)")
+	bakeCmd.Flags().StringVar(&rejectsFile, "rejects", "", "--rejects /path/to/rejects.csv (write rows with errors here, implies --on-error=reject)")
+	bakeCmd.Flags().StringVar(&outputFormat, "format", "csv", "--format=json (csv, json, ndjson, sql, markdown, html or fixed)")
+	bakeCmd.Flags().BoolVar(&htmlPage, "html-page", false, "--html-page (write a standalone page with styling, not just the table, with --format=html)")
+	bakeCmd.Flags().StringVar(&sqlTable, "table", "", "--table voters (table to create and insert into with --format=sql)")
+	bakeCmd.Flags().StringVar(&sqlDialect, "sql-dialect", "postgres", "--sql-dialect=mysql (postgres, mysql or sqlite)")
+	bakeCmd.Flags().IntVar(&sqlBatchSize, "batch-size", 100, "--batch-size 500 (rows in each INSERT statement with --format=sql)")
+	bakeCmd.Flags().StringVar(&inFormat, "in-format", "", "--in-format=json (csv, json or fixed, by default from the input file extension)")
+	bakeCmd.Flags().StringVar(&inLayout, "in-layout", "", "--in-layout /path/to/layout.csv (read fixed-width input)")
+	bakeCmd.Flags().StringVar(&outLayout, "out-layout", "", "--out-layout /path/to/layout.csv (write fixed-width output)")
+	bakeCmd.Flags().StringVar(&inputEncoding, "input-encoding", "auto", "--input-encoding=windows-1252 (auto, utf-8, utf-8-bom, utf-16le, utf-16be, windows-1252 or iso-8859-1)")
+	bakeCmd.Flags().StringVar(&outputEncoding, "output-encoding", "utf-8", "--output-encoding=utf-16le (utf-8, utf-8-bom, utf-16le, utf-16be, windows-1252 or iso-8859-1)")
+	bakeCmd.Flags().StringVar(&splitBy, "split-by", "", "--split-by 3 (write a file for each value of an output column or $variable)")
+	bakeCmd.Flags().IntVar(&maxRows, "max-rows", 0, "--max-rows 50000 (start a new output file after this many lines)")
+	bakeCmd.Flags().StringVar(&overflow, "overflow", "reject", "--overflow=truncate (truncate or reject values too long for fixed-width fields)")
+	addDialectFlags(bakeCmd, inputDialectFlags)
+	addDialectFlags(bakeCmd, outputDialectFlags)
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// bakeCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
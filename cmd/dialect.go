@@ -0,0 +1,72 @@
+/*
+Copyright © 2021 David Stockton <dave@davidstockton.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"github.com/dstockto/csv-chef/csv"
+	"github.com/spf13/cobra"
+)
+
+// dialectFlag is a command line flag that sets an option of a csv.Dialect. Flags override the recipe.
+type dialectFlag struct {
+	name    string
+	option  string
+	boolean bool
+	usage   string
+}
+
+var inputDialectFlags = []dialectFlag{
+	{name: "in-delimiter", option: "delimiter", usage: `--in-delimiter ";" (field separator of the input, tab for a tab)`},
+	{name: "in-quote", option: "quote", usage: `--in-quote "'" (quote character of the input)`},
+	{name: "comment", option: "comment", usage: `--comment "#" (skip input lines starting with this)`},
+	{name: "lazy-quotes", option: "lazyquotes", boolean: true, usage: "--lazy-quotes (allow stray quotes in the input)"},
+}
+
+var outputDialectFlags = []dialectFlag{
+	{name: "out-delimiter", option: "delimiter", usage: `--out-delimiter "|" (field separator of the output, tab for a tab)`},
+	{name: "out-quote", option: "quote", usage: `--out-quote "'" (quote character of the output)`},
+	{name: "always-quote", option: "alwaysquote", boolean: true, usage: "--always-quote (quote every output field)"},
+	{name: "crlf", option: "crlf", boolean: true, usage: "--crlf (end output lines with \\r\\n)"},
+}
+
+func addDialectFlags(cmd *cobra.Command, flags []dialectFlag) {
+	for _, f := range flags {
+		if f.boolean {
+			cmd.Flags().Bool(f.name, false, f.usage)
+		} else {
+			cmd.Flags().String(f.name, "", f.usage)
+		}
+	}
+}
+
+// dialectFromFlags applies the flags that were given to the dialect.
+func dialectFromFlags(cmd *cobra.Command, dialect csv.Dialect, flags []dialectFlag) (csv.Dialect, error) {
+	for _, f := range flags {
+		if !cmd.Flags().Changed(f.name) {
+			continue
+		}
+		if err := dialect.Set(f.option, cmd.Flags().Lookup(f.name).Value.String()); err != nil {
+			return dialect, err
+		}
+	}
+	return dialect, dialect.Validate()
+}
@@ -0,0 +1,56 @@
+/*
+Copyright © 2021 David Stockton <dave@davidstockton.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/dstockto/csv-chef/recipe"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// functionsCmd represents the functions command
+var functionsCmd = &cobra.Command{
+	Use:   "functions",
+	Short: "Lists the functions available to recipes",
+	Long: `Lists every function that can be used in a recipe along with its arguments and a short
+description. Function names are case-insensitive and aliases can be used in place of the name.`,
+	Run: runFunctions,
+}
+
+func runFunctions(cmd *cobra.Command, args []string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, f := range recipe.DefaultRegistry.Functions() {
+		description := f.Description
+		if len(f.Aliases) > 0 {
+			description += fmt.Sprintf(" (aliases: %s)", strings.Join(f.Aliases, ", "))
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", f.Signature(), description)
+	}
+	_ = w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(functionsCmd)
+}
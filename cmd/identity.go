@@ -23,17 +23,21 @@ package cmd
 
 import (
 	"bytes"
-	"encoding/csv"
 	"errors"
 	"fmt"
+	"github.com/dstockto/csv-chef/csv"
+	"github.com/dstockto/csv-chef/recipe"
 	"github.com/google/martian/log"
 	"github.com/spf13/cobra"
 	"io"
 	"os"
+	"strconv"
+	"strings"
 )
 
 var (
 	withHeaders bool
+	withNames   bool
 	output      string
 )
 
@@ -44,7 +48,11 @@ var identityCmd = &cobra.Command{
 	Long: `The identity command creates a recipe that will read in and write out a file
 unchanged from a given input file. This can then be used to build the recipe you want without
 needing to specify all the columns (and headers, optionally) through typing. The intent is to
-save you some time. To save it to a file, redirect the output to a file or provide the -o flag.`,
+save you some time. To save it to a file, redirect the output to a file or provide the -o flag. The -N flag
+references the input columns by their header name instead of their position, so the recipe keeps working
+if the columns are moved around. The input dialect flags, like --in-delimiter, describe the input and are
+written to the recipe as an @input line. JSON input, chosen by the file extension or --in-format, gets an
+@fields line listing the field paths of the first object.`,
 	Run: runIdentity,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
@@ -56,13 +64,32 @@ save you some time. To save it to a file, redirect the output to a file or provi
 
 func runIdentity(cmd *cobra.Command, args []string) {
 	// try to read file
-	in, err := os.Open(args[0])
+	in, err := csv.OpenInput(args[0])
 	if err != nil {
 		log.Errorf("Unable to read input file: %v", err)
 		os.Exit(3)
 	}
+	defer in.Close()
+	if err := in.Decode(inputEncoding); err != nil {
+		log.Errorf("Invalid input encoding: %v", err)
+		os.Exit(1)
+	}
 
-	csvReader := csv.NewReader(in)
+	format, err := inputFormat(args[0], inFormat, inLayout)
+	if err != nil {
+		log.Errorf("%v", err)
+		os.Exit(1)
+	}
+	dialect, err := dialectFromFlags(cmd, csv.Dialect{}, inputDialectFlags)
+	if err != nil {
+		log.Errorf("Invalid input dialect: %v", err)
+		os.Exit(1)
+	}
+	csvReader, err := newInputReader(in, format, dialect, nil, inLayout)
+	if err != nil {
+		log.Errorf("Unable to read input file: %v", err)
+		os.Exit(3)
+	}
 	row, err := csvReader.Read()
 	if err == io.EOF {
 		log.Errorf("Input CSV was empty")
@@ -95,17 +122,41 @@ func runIdentity(cmd *cobra.Command, args []string) {
 		w = os.Stdout
 	}
 
+	if options := dialect.String(); options != "" && format == "csv" {
+		_, err = fmt.Fprintf(w, "@input %s\n", options)
+		if err != nil {
+			log.Errorf("Error writing input dialect: %v", err)
+			os.Exit(10)
+		}
+	}
+	if format == "json" {
+		// declare the fields so the columns don't depend on the order of the first object
+		fields := make([]string, len(row))
+		for i, field := range row {
+			fields[i] = strconv.Quote(field)
+		}
+		_, err = fmt.Fprintf(w, "@fields %s\n", strings.Join(fields, " "))
+		if err != nil {
+			log.Errorf("Error writing input fields: %v", err)
+			os.Exit(10)
+		}
+	}
+
 	for zeroIndex, column := range row {
 		num := zeroIndex + 1
+		source := strconv.Itoa(num)
+		if withNames {
+			source = recipe.ColumnNameRef(strings.TrimSpace(trimBom(column)))
+		}
 		if withHeaders {
-			_, err = fmt.Fprintf(w, "!%d <- %d # %s header\n", num, num, trimBom(column))
-			_, err = fmt.Fprintf(w, "%d <- %d # %s\n", num, num, trimBom(column))
+			_, err = fmt.Fprintf(w, "!%d <- %s # %s header\n", num, source, trimBom(column))
+			_, err = fmt.Fprintf(w, "%d <- %s # %s\n", num, source, trimBom(column))
 			if err != nil {
 				log.Errorf("Error writing header recipe: %v", err)
 				os.Exit(10)
 			}
 		} else {
-			_, err = fmt.Fprintf(w, "%d <- %d\n", num, num)
+			_, err = fmt.Fprintf(w, "%d <- %s\n", num, source)
 			if err != nil {
 				log.Errorf("Error writing recipe line: %v", err)
 				os.Exit(11)
@@ -123,8 +174,13 @@ func init() {
 	// and all subcommands, e.g.:
 	// identityCmd.PersistentFlags().String("foo", "", "A help for foo")
 	identityCmd.Flags().BoolVarP(&withHeaders, "with-headers", "w", false, "--with-headers")
+	identityCmd.Flags().BoolVarP(&withNames, "names", "N", false, "--names (reference columns by header name)")
 	identityCmd.Flags().StringVarP(&output, "output", "o", "", "-o /path/to/output.csv")
 	identityCmd.Flags().BoolVarP(&forceOverwrite, "force", "f", false, "-f (write file even if it exists)")
+	identityCmd.Flags().StringVar(&inFormat, "in-format", "", "--in-format=json (csv, json or fixed, by default from the input file extension)")
+	identityCmd.Flags().StringVar(&inLayout, "in-layout", "", "--in-layout /path/to/layout.csv (read fixed-width input)")
+	identityCmd.Flags().StringVar(&inputEncoding, "input-encoding", "auto", "--input-encoding=windows-1252 (auto, utf-8, utf-8-bom, utf-16le, utf-16be, windows-1252 or iso-8859-1)")
+	addDialectFlags(identityCmd, inputDialectFlags)
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// identityCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
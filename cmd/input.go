@@ -0,0 +1,78 @@
+/*
+Copyright © 2021 David Stockton <dave@davidstockton.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/dstockto/csv-chef/csv"
+	"github.com/dstockto/csv-chef/recipe"
+)
+
+// inputFormat decides how to read the input from the --in-format flag, or when the flag isn't given, from the
+// --in-layout flag or the file's extension. The formats are csv, json, which reads NDJSON as well as an array of
+// objects, and fixed for fixed-width files.
+func inputFormat(path string, format string, layoutPath string) (string, error) {
+	switch strings.ToLower(format) {
+	case "csv":
+		return "csv", nil
+	case "json", "ndjson", "jsonl":
+		return "json", nil
+	case "fixed":
+		if layoutPath == "" {
+			return "", fmt.Errorf("fixed-width input needs a layout, give it with --in-layout")
+		}
+		return "fixed", nil
+	case "":
+		if layoutPath != "" {
+			return "fixed", nil
+		}
+		switch filepath.Ext(strings.TrimSuffix(strings.ToLower(path), ".gz")) {
+		case ".json", ".ndjson", ".jsonl":
+			return "json", nil
+		}
+		return "csv", nil
+	}
+	return "", fmt.Errorf("unrecognized input format '%s', expected csv, json, ndjson or fixed", format)
+}
+
+// newInputReader returns the reader for the input format. The fields are the JSON field paths for the columns, or nil
+// to use the fields of the first object. The layout is only needed for fixed-width input.
+func newInputReader(in io.Reader, format string, dialect csv.Dialect, fields []string, layoutPath string) (recipe.RowReader, error) {
+	if format != "json" && len(fields) > 0 {
+		return nil, fmt.Errorf("@fields is only used for JSON input")
+	}
+	switch format {
+	case "json":
+		return csv.NewJSONReader(in, fields), nil
+	case "fixed":
+		layout, err := csv.LoadLayout(layoutPath)
+		if err != nil {
+			return nil, err
+		}
+		return csv.NewFixedWidthReader(in, layout), nil
+	}
+	return csv.NewReader(in, dialect)
+}
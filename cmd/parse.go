@@ -23,10 +23,13 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"github.com/dstockto/csv-chef/recipe"
 	"github.com/google/martian/log"
 	"github.com/spf13/cobra"
+	"io"
 	"os"
+	"strings"
 )
 
 // parseCmd represents the parse command
@@ -44,22 +47,71 @@ var parseFakeCmd = &cobra.Command{
 }
 
 func runParse(cmd *cobra.Command, args []string) {
-	recipeFile, err := os.Open(args[0])
-	if err != nil {
+	if _, err := os.Stat(args[0]); err != nil {
 		log.Errorf("%+v\n", err)
 		os.Exit(2)
 	}
-	transformation, err := recipe.Parse(recipeFile)
+	params, err := paramsFromFlags(recipeParams)
 	if err != nil {
-		log.Errorf("%+v\n", err)
+		log.Errorf("Invalid parameter: %v", err)
+		os.Exit(1)
+	}
+	transformation, err := recipe.ParseFileWithOptions(args[0], recipe.ParseOptions{Params: params})
+	if err != nil {
+		if !printParseErrors(os.Stderr, args[0], err) {
+			log.Errorf("%+v\n", err)
+		}
 		os.Exit(10)
 	}
 
 	transformation.Dump(os.Stdout)
 }
 
+// paramsFromFlags reads the recipe parameters given with --set, each as name=value.
+func paramsFromFlags(values []string) (map[string]string, error) {
+	params := make(map[string]string)
+	for _, v := range values {
+		name, value := v, ""
+		i := strings.Index(v, "=")
+		if i >= 0 {
+			name, value = v[:i], v[i+1:]
+		}
+		name = strings.TrimPrefix(strings.TrimSpace(name), "$param.")
+		if i < 0 || name == "" {
+			return nil, fmt.Errorf("expected name=value, found %s", v)
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+// printParseErrors prints the mistakes in a recipe the way a compiler would, each with the line of the recipe and a
+// caret under the mistake. It returns false, without printing anything, if the error isn't from parsing the recipe.
+func printParseErrors(w io.Writer, path string, err error) bool {
+	var errs recipe.ParseErrors
+	if !errors.As(err, &errs) {
+		return false
+	}
+	for _, e := range errs {
+		file := path
+		if e.File != "" {
+			file = e.File
+		}
+		if e.Line == 0 {
+			_, _ = fmt.Fprintf(w, "%s: error: %s\n", file, e.Message)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s:%d:%d: error: %s\n%s\n", file, e.Line, e.Column, e.Message, e.Caret())
+	}
+	if len(errs) > 1 {
+		_, _ = fmt.Fprintf(w, "%d errors in %s\n", len(errs), path)
+	}
+	return true
+}
+
 func init() {
 	rootCmd.AddCommand(parseFakeCmd)
+	parseFakeCmd.Flags().StringArrayVar(&recipeParams, "set", nil, "--set batch=2026Q3 (value of a recipe parameter, read as $param.batch, repeat for more than one)")
 
 	// Here you will define your flags and configuration settings.
 
@@ -0,0 +1,89 @@
+/*
+Copyright © 2021 David Stockton <dave@davidstockton.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dstockto/csv-chef/recipe"
+)
+
+const progressBarWidth = 30
+
+// progressBar draws a single line showing how far baking has gotten, rewriting it as progress is reported.
+type progressBar struct {
+	out        io.Writer
+	totalBytes int64 // size of the input, or 0 if it isn't known
+	start      time.Time
+	drawn      bool
+}
+
+func newProgressBar(out io.Writer, totalBytes int64) *progressBar {
+	return &progressBar{out: out, totalBytes: totalBytes, start: time.Now()}
+}
+
+// isTerminal reports whether the file is a terminal rather than a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Update redraws the bar for the given progress.
+func (b *progressBar) Update(p recipe.Progress) {
+	elapsed := time.Since(b.start)
+	rate := float64(p.RowsRead) / elapsed.Seconds()
+
+	line := fmt.Sprintf("%d rows  %.0f rows/s", p.RowsRead, rate)
+	if b.totalBytes > 0 && p.BytesRead >= 0 {
+		fraction := float64(p.BytesRead) / float64(b.totalBytes)
+		if fraction > 1 {
+			fraction = 1
+		}
+		filled := int(fraction * progressBarWidth)
+		bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+
+		eta := "--"
+		if fraction > 0 {
+			remaining := time.Duration(float64(elapsed) * (1 - fraction) / fraction)
+			eta = remaining.Round(time.Second).String()
+		}
+		line = fmt.Sprintf("[%s] %3.0f%%  %s  ETA %s", bar, fraction*100, line, eta)
+	}
+
+	// pad so a shorter line completely covers the previous one
+	_, _ = fmt.Fprintf(b.out, "\r%-80s", line)
+	b.drawn = true
+}
+
+// Clear removes the bar so the summary can be printed on a clean line.
+func (b *progressBar) Clear() {
+	if b.drawn {
+		_, _ = fmt.Fprintf(b.out, "\r%80s\r", "")
+	}
+}
@@ -46,9 +46,27 @@ var readCmd = &cobra.Command{
 }
 
 func runRead(cmd *cobra.Command, args []string) {
-	csvFile, closeFunc, err := csv.NewCsvSource(args[0])
-	defer closeFunc()
-
+	format, err := inputFormat(args[0], inFormat, inLayout)
+	if err != nil {
+		log.Errorf("%v", err)
+		os.Exit(1)
+	}
+	dialect, err := dialectFromFlags(cmd, csv.Dialect{}, inputDialectFlags)
+	if err != nil {
+		log.Errorf("Invalid input dialect: %v", err)
+		os.Exit(1)
+	}
+	file, err := csv.OpenInput(args[0])
+	if err != nil {
+		log.Errorf("%+v", err)
+		os.Exit(1)
+	}
+	if err := file.Decode(inputEncoding); err != nil {
+		log.Errorf("Invalid input encoding: %v", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+	csvFile, err := newInputReader(file, format, dialect, nil, inLayout)
 	if err != nil {
 		log.Errorf("%+v", err)
 		os.Exit(1)
@@ -59,12 +77,20 @@ func runRead(cmd *cobra.Command, args []string) {
 		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			log.Errorf("%+v", err)
+			os.Exit(1)
+		}
 		fmt.Println(line)
 	}
 }
 
 func init() {
 	rootCmd.AddCommand(readCmd)
+	readCmd.Flags().StringVar(&inFormat, "in-format", "", "--in-format=json (csv, json or fixed, by default from the file extension)")
+	readCmd.Flags().StringVar(&inLayout, "in-layout", "", "--in-layout /path/to/layout.csv (read fixed-width input)")
+	readCmd.Flags().StringVar(&inputEncoding, "input-encoding", "auto", "--input-encoding=windows-1252 (auto, utf-8, utf-8-bom, utf-16le, utf-16be, windows-1252 or iso-8859-1)")
+	addDialectFlags(readCmd, inputDialectFlags)
 
 	// Here you will define your flags and configuration settings.
 
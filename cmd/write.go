@@ -48,7 +48,7 @@ var writeCmd = &cobra.Command{
 var lines int
 
 func runWrite(cmd *cobra.Command, args []string) {
-	output, closeFunc, err := csv.NewOutputSource(args[0])
+	output, closeFunc, err := csv.NewOutputSource(args[0], csv.Dialect{})
 	defer closeFunc()
 
 	if err != nil {
@@ -0,0 +1,140 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Dialect describes how a CSV file is laid out. The zero value is the standard dialect: fields separated by commas,
+// quoted with " when they need it and lines ending with \n.
+type Dialect struct {
+	Delimiter   rune // separates the fields, 0 means ,
+	Quote       rune // surrounds fields that contain special characters, 0 means "
+	Comment     rune // lines starting with it are skipped when reading, 0 means there are no comments
+	LazyQuotes  bool // allow quotes in unquoted fields and unescaped quotes in quoted fields when reading
+	AlwaysQuote bool // quote every field when writing, not just the ones that need it
+	CRLF        bool // end lines with \r\n when writing
+}
+
+// dialectOptions are the names of the options that can be set on a Dialect, in the order they are described.
+var dialectOptions = []string{"delimiter", "quote", "comment", "lazyquotes", "alwaysquote", "crlf"}
+
+func (d Dialect) delimiter() rune {
+	if d.Delimiter == 0 {
+		return ','
+	}
+	return d.Delimiter
+}
+
+func (d Dialect) quote() rune {
+	if d.Quote == 0 {
+		return '"'
+	}
+	return d.Quote
+}
+
+// Set changes one option of the dialect by name. Character options take a single character, or tab for a tab.
+// Boolean options take true or false, an empty value means true.
+func (d *Dialect) Set(option string, value string) error {
+	var err error
+	switch strings.ToLower(option) {
+	case "delimiter":
+		d.Delimiter, err = parseDialectRune(option, value)
+	case "quote":
+		d.Quote, err = parseDialectRune(option, value)
+	case "comment":
+		d.Comment, err = parseDialectRune(option, value)
+	case "lazyquotes":
+		d.LazyQuotes, err = parseDialectBool(option, value)
+	case "alwaysquote":
+		d.AlwaysQuote, err = parseDialectBool(option, value)
+	case "crlf":
+		d.CRLF, err = parseDialectBool(option, value)
+	default:
+		return fmt.Errorf("unrecognized dialect option %s, expected one of %s", option, strings.Join(dialectOptions, ", "))
+	}
+	return err
+}
+
+func parseDialectRune(option string, value string) (rune, error) {
+	switch strings.ToLower(value) {
+	case "tab", `\t`:
+		return '\t', nil
+	case "":
+		return 0, fmt.Errorf("dialect option %s needs a character", option)
+	}
+	r, size := utf8.DecodeRuneInString(value)
+	if size != len(value) {
+		return 0, fmt.Errorf("dialect option %s must be a single character, found %s", option, value)
+	}
+	return r, nil
+}
+
+func parseDialectBool(option string, value string) (bool, error) {
+	if value == "" {
+		return true, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("dialect option %s must be true or false, found %s", option, value)
+	}
+	return b, nil
+}
+
+// Validate makes sure the dialect's characters can be told apart from each other and from line endings.
+func (d Dialect) Validate() error {
+	delimiter, quote := d.delimiter(), d.quote()
+	for _, r := range []rune{delimiter, quote, d.Comment} {
+		if r == '\r' || r == '\n' || r == utf8.RuneError {
+			return fmt.Errorf("%q cannot be used in a dialect", r)
+		}
+	}
+	if quote >= utf8.RuneSelf {
+		return fmt.Errorf("quote must be a single byte character, found %q", quote)
+	}
+	if delimiter == quote {
+		return errors.New("delimiter and quote must be different")
+	}
+	if d.Comment == delimiter || d.Comment == quote {
+		return errors.New("comment must be different from the delimiter and quote")
+	}
+	return nil
+}
+
+// String describes the options that differ from the standard dialect the way they are written in a recipe, ex:
+// delimiter ";" crlf
+func (d Dialect) String() string {
+	var options []string
+	if d.delimiter() != ',' {
+		options = append(options, "delimiter "+quoteDialectRune(d.delimiter()))
+	}
+	if d.quote() != '"' {
+		options = append(options, "quote "+quoteDialectRune(d.quote()))
+	}
+	if d.Comment != 0 {
+		options = append(options, "comment "+quoteDialectRune(d.Comment))
+	}
+	if d.LazyQuotes {
+		options = append(options, "lazyquotes")
+	}
+	if d.AlwaysQuote {
+		options = append(options, "alwaysquote")
+	}
+	if d.CRLF {
+		options = append(options, "crlf")
+	}
+	return strings.Join(options, " ")
+}
+
+func quoteDialectRune(r rune) string {
+	switch r {
+	case '\t':
+		return `"tab"`
+	case '"', '\\':
+		return `"\` + string(r) + `"`
+	}
+	return `"` + string(r) + `"`
+}
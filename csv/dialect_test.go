@@ -0,0 +1,214 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReader_Read(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		input   string
+		want    [][]string
+	}{
+		{
+			name:  "standard",
+			input: "a,\"b,c\"\n\"d\"\"e\",\n",
+			want:  [][]string{{"a", "b,c"}, {"d\"e", ""}},
+		},
+		{
+			name:    "semicolons",
+			dialect: Dialect{Delimiter: ';'},
+			input:   "a;b\n\"c;d\";e\n",
+			want:    [][]string{{"a", "b"}, {"c;d", "e"}},
+		},
+		{
+			name:    "tabs",
+			dialect: Dialect{Delimiter: '\t'},
+			input:   "a\tb,c\n",
+			want:    [][]string{{"a", "b,c"}},
+		},
+		{
+			name:    "single quotes with double quotes in the data",
+			dialect: Dialect{Quote: '\''},
+			input:   "'it''s','say \"hi\"'\n",
+			want:    [][]string{{"it's", "say \"hi\""}},
+		},
+		{
+			name:    "comments and lazy quotes",
+			dialect: Dialect{Comment: '#', LazyQuotes: true},
+			input:   "# skipped\na\"b\n",
+			want:    [][]string{{"a\"b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(tt.input), tt.dialect)
+			if err != nil {
+				t.Fatalf("reader error = %v", err)
+			}
+			var got [][]string
+			for {
+				row, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("read error = %v", err)
+				}
+				got = append(got, row)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriter_Write(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		rows    [][]string
+		want    string
+	}{
+		{
+			name: "standard",
+			rows: [][]string{{"a", "b,c", ""}, {"d\"e", " f", `\.`}},
+			want: "a,\"b,c\",\n\"d\"\"e\",\" f\",\"\\.\"\n",
+		},
+		{
+			name:    "pipes",
+			dialect: Dialect{Delimiter: '|'},
+			rows:    [][]string{{"a|b", "c,d"}},
+			want:    "\"a|b\"|c,d\n",
+		},
+		{
+			name:    "single quotes",
+			dialect: Dialect{Quote: '\''},
+			rows:    [][]string{{"it's", "say \"hi\""}},
+			want:    "'it''s',say \"hi\"\n",
+		},
+		{
+			name:    "always quote with crlf",
+			dialect: Dialect{AlwaysQuote: true, CRLF: true},
+			rows:    [][]string{{"a", ""}, {"b", "two\nlines"}},
+			want:    "\"a\",\"\"\r\n\"b\",\"two\r\nlines\"\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w, err := NewWriter(&b, tt.dialect)
+			if err != nil {
+				t.Fatalf("writer error = %v", err)
+			}
+			for _, row := range tt.rows {
+				if err := w.Write(row); err != nil {
+					t.Fatalf("write error = %v", err)
+				}
+			}
+			w.Flush()
+			if err := w.Error(); err != nil {
+				t.Fatalf("flush error = %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialect_Set(t *testing.T) {
+	tests := []struct {
+		name        string
+		option      string
+		value       string
+		want        Dialect
+		wantErrText string
+	}{
+		{name: "delimiter", option: "delimiter", value: ";", want: Dialect{Delimiter: ';'}},
+		{name: "tab", option: "Delimiter", value: "tab", want: Dialect{Delimiter: '\t'}},
+		{name: "flag without a value", option: "crlf", want: Dialect{CRLF: true}},
+		{name: "flag with a value", option: "lazyquotes", value: "false", want: Dialect{}},
+		{
+			name:        "unknown option",
+			option:      "separator",
+			value:       ";",
+			wantErrText: "unrecognized dialect option separator, expected one of delimiter, quote, comment, lazyquotes, alwaysquote, crlf",
+		},
+		{name: "single characters only", option: "delimiter", value: ";;", wantErrText: "dialect option delimiter must be a single character, found ;;"},
+		{name: "characters are needed", option: "quote", wantErrText: "dialect option quote needs a character"},
+		{name: "flags are bools", option: "alwaysquote", value: "always", wantErrText: "dialect option alwaysquote must be true or false, found always"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Dialect
+			err := d.Set(tt.option, tt.value)
+			if tt.wantErrText != "" {
+				if err == nil || err.Error() != tt.wantErrText {
+					t.Errorf("error = %v, want %v", err, tt.wantErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if d != tt.want {
+				t.Errorf("dialect = %+v, want %+v", d, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialect_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		dialect     Dialect
+		wantErrText string
+	}{
+		{name: "standard", dialect: Dialect{}},
+		{name: "delimiter and quote differ", dialect: Dialect{Delimiter: '"'}, wantErrText: "delimiter and quote must be different"},
+		{name: "comment differs", dialect: Dialect{Delimiter: ';', Comment: ';'}, wantErrText: "comment must be different from the delimiter and quote"},
+		{name: "no line endings", dialect: Dialect{Delimiter: '\n'}, wantErrText: "'\\n' cannot be used in a dialect"},
+		{name: "single byte quote", dialect: Dialect{Quote: '»'}, wantErrText: "quote must be a single byte character, found '»'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dialect.Validate()
+			if tt.wantErrText == "" {
+				if err != nil {
+					t.Errorf("error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErrText {
+				t.Errorf("error = %v, want %v", err, tt.wantErrText)
+			}
+		})
+	}
+}
+
+func TestDialect_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		want    string
+	}{
+		{name: "standard", dialect: Dialect{}, want: ""},
+		{name: "standard spelled out", dialect: Dialect{Delimiter: ',', Quote: '"'}, want: ""},
+		{name: "tab and single quote", dialect: Dialect{Delimiter: '\t', Quote: '\''}, want: `delimiter "tab" quote "'"`},
+		{name: "flags", dialect: Dialect{Comment: '#', LazyQuotes: true, AlwaysQuote: true, CRLF: true}, want: `comment "#" lazyquotes alwaysquote crlf`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
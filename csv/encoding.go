@@ -0,0 +1,114 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// encodingNames maps the accepted names of character encodings to the name they are known by here.
+var encodingNames = map[string]string{
+	"auto":         "auto",
+	"utf-8":        "utf-8",
+	"utf8":         "utf-8",
+	"utf-8-bom":    "utf-8-bom",
+	"utf8bom":      "utf-8-bom",
+	"utf-16le":     "utf-16le",
+	"utf16le":      "utf-16le",
+	"utf-16be":     "utf-16be",
+	"utf16be":      "utf-16be",
+	"windows-1252": "windows-1252",
+	"cp1252":       "windows-1252",
+	"iso-8859-1":   "iso-8859-1",
+	"latin1":       "iso-8859-1",
+}
+
+func encodingName(name string) (string, error) {
+	canonical, ok := encodingNames[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("unrecognized encoding '%s', expected auto, utf-8, utf-8-bom, utf-16le, utf-16be, windows-1252 or iso-8859-1", name)
+	}
+	return canonical, nil
+}
+
+// NewDecoder returns a reader that turns input in the encoding into UTF-8, without a byte order mark. Auto uses the
+// byte order mark to tell UTF-8 from UTF-16, and passes input without one through unchanged.
+func NewDecoder(r io.Reader, encoding string) (io.Reader, error) {
+	name, err := encodingName(encoding)
+	if err != nil {
+		return nil, err
+	}
+	var decoder transform.Transformer
+	switch name {
+	case "auto":
+		decoder = unicode.BOMOverride(transform.Nop)
+	case "utf-8", "utf-8-bom":
+		decoder = unicode.UTF8BOM.NewDecoder()
+	case "utf-16le":
+		decoder = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder()
+	case "utf-16be":
+		decoder = unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder()
+	case "windows-1252":
+		decoder = charmap.Windows1252.NewDecoder()
+	case "iso-8859-1":
+		decoder = charmap.ISO8859_1.NewDecoder()
+	}
+	return transform.NewReader(r, decoder), nil
+}
+
+// NewEncoder returns a writer that turns UTF-8 into the encoding. UTF-16 and utf-8-bom start with a byte order mark.
+// Characters that the encoding doesn't have are an error. It must be closed to write the last of the output.
+func NewEncoder(w io.Writer, encoding string) (io.WriteCloser, error) {
+	name, err := encodingName(encoding)
+	if err != nil {
+		return nil, err
+	}
+	var encoder transform.Transformer
+	switch name {
+	case "auto":
+		return nil, fmt.Errorf("auto can only be used for input")
+	case "utf-8":
+		encoder = transform.Nop
+	case "utf-8-bom":
+		encoder = unicode.UTF8BOM.NewEncoder()
+	case "utf-16le":
+		encoder = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder()
+	case "utf-16be":
+		encoder = unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewEncoder()
+	case "windows-1252":
+		encoder = charmap.Windows1252.NewEncoder()
+	case "iso-8859-1":
+		encoder = charmap.ISO8859_1.NewEncoder()
+	}
+	return transform.NewWriter(w, encoder), nil
+}
+
+// Decode turns the input into UTF-8 from the encoding as it is read, see NewDecoder.
+func (i *Input) Decode(encoding string) error {
+	decoded, err := NewDecoder(i.Reader, encoding)
+	if err != nil {
+		return err
+	}
+	i.Reader = decoded
+	// offsets into the decoded input no longer match the size of the file
+	if name, _ := encodingName(encoding); name != "auto" && name != "utf-8" && name != "utf-8-bom" {
+		i.Size = 0
+	}
+	return nil
+}
+
+// Encode turns what is written to the output into the encoding, see NewEncoder. Closing the output finishes the
+// encoding.
+func (o *Output) Encode(encoding string) error {
+	encoded, err := NewEncoder(o.Writer, encoding)
+	if err != nil {
+		return err
+	}
+	o.Writer = encoded
+	o.closers = append([]func() error{encoded.Close}, o.closers...)
+	return nil
+}
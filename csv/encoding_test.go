@@ -0,0 +1,97 @@
+package csv
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestNewDecoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		encoding string
+		want     string
+	}{
+		{name: "utf-8 byte order mark is removed", input: "\xef\xbb\xbfZoë\n", encoding: "auto", want: "Zoë\n"},
+		{name: "utf-16 is found by its byte order mark", input: "\xff\xfeZ\x00o\x00\xeb\x00\n\x00", encoding: "auto", want: "Zoë\n"},
+		{name: "without a byte order mark input is unchanged", input: "Zo\xeb\n", encoding: "auto", want: "Zo\xeb\n"},
+		{name: "utf-8 without a byte order mark", input: "Zoë\n", encoding: "utf8", want: "Zoë\n"},
+		{name: "utf-16be", input: "\x00Z\x00o\x00\xeb", encoding: "utf-16be", want: "Zoë"},
+		{name: "windows-1252", input: "Zo\xeb \x80", encoding: "cp1252", want: "Zoë €"},
+		{name: "iso-8859-1", input: "Zo\xeb", encoding: "latin1", want: "Zoë"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := NewDecoder(strings.NewReader(tt.input), tt.encoding)
+			if err != nil {
+				t.Fatalf("decoder error = %v", err)
+			}
+			got, err := ioutil.ReadAll(decoded)
+			if err != nil {
+				t.Fatalf("read error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("decoded = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEncoder(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		encoding    string
+		want        string
+		wantErrText string
+	}{
+		{name: "utf-8 is unchanged", input: "Zoë\n", encoding: "utf-8", want: "Zoë\n"},
+		{name: "utf-8 with a byte order mark", input: "Zoë\n", encoding: "utf-8-bom", want: "\xef\xbb\xbfZoë\n"},
+		{name: "utf-16le", input: "Zoë€", encoding: "utf-16le", want: "\xff\xfeZ\x00o\x00\xeb\x00\xac\x20"},
+		{name: "utf-16be", input: "Zoë", encoding: "UTF-16BE", want: "\xfe\xff\x00Z\x00o\x00\xeb"},
+		{name: "windows-1252", input: "Zoë €", encoding: "windows-1252", want: "Zo\xeb \x80"},
+		{
+			name:        "characters missing from the encoding",
+			input:       "Zoë,東京\n",
+			encoding:    "iso-8859-1",
+			wantErrText: "encoding: rune not supported by encoding.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			encoded, err := NewEncoder(&b, tt.encoding)
+			if err != nil {
+				t.Fatalf("encoder error = %v", err)
+			}
+			_, err = encoded.Write([]byte(tt.input))
+			if err == nil {
+				err = encoded.Close()
+			}
+			if tt.wantErrText != "" {
+				if err == nil || err.Error() != tt.wantErrText {
+					t.Errorf("error = %v, want %v", err, tt.wantErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("encode error = %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("encoded = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDecoder_UnknownEncoding(t *testing.T) {
+	want := "unrecognized encoding 'ebcdic', expected auto, utf-8, utf-8-bom, utf-16le, utf-16be, windows-1252 or iso-8859-1"
+	if _, err := NewDecoder(strings.NewReader(""), "ebcdic"); err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %v", err, want)
+	}
+	if _, err := NewEncoder(&bytes.Buffer{}, "auto"); err == nil || err.Error() != "auto can only be used for input" {
+		t.Errorf("error = %v, want auto can only be used for input", err)
+	}
+}
@@ -0,0 +1,176 @@
+package csv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// FixedWidthReader reads the lines of a fixed-width file as rows, with a value for each field of the layout. The
+// first row is a header of the field names. Padding is removed from the values and blank lines are skipped.
+type FixedWidthReader struct {
+	r      *bufio.Reader
+	layout *Layout
+	header bool
+	line   int
+	offset int64
+}
+
+func NewFixedWidthReader(r io.Reader, layout *Layout) *FixedWidthReader {
+	return &FixedWidthReader{r: bufio.NewReader(r), layout: layout}
+}
+
+// Read returns the header of field names first, then the fields of each line. A line that is shorter than the layout
+// has empty values for the fields it doesn't reach.
+func (r *FixedWidthReader) Read() ([]string, error) {
+	if !r.header {
+		r.header = true
+		names := make([]string, len(r.layout.Fields))
+		for i, f := range r.layout.Fields {
+			names[i] = f.Name
+		}
+		return names, nil
+	}
+
+	for {
+		line, err := r.r.ReadString('\n')
+		r.offset += int64(len(line))
+		if line == "" && err != nil {
+			return nil, err
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		r.line++
+
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if r.line == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		return r.fields([]rune(line)), nil
+	}
+}
+
+func (r *FixedWidthReader) fields(line []rune) []string {
+	row := make([]string, len(r.layout.Fields))
+	for i, f := range r.layout.Fields {
+		start := f.Start - 1
+		if start >= len(line) {
+			continue
+		}
+		end := start + f.Len
+		if end > len(line) {
+			end = len(line)
+		}
+		value := string(line[start:end])
+
+		pad := string(f.pad())
+		trimmed := strings.TrimRight(value, pad)
+		if f.Right {
+			trimmed = strings.TrimLeft(value, pad)
+		}
+		// a field that is all zeros is a zero, not an empty value
+		if trimmed == "" && value != "" && f.pad() != ' ' {
+			trimmed = pad
+		}
+		row[i] = trimmed
+	}
+	return row
+}
+
+// InputOffset returns how many bytes have been read.
+func (r *FixedWidthReader) InputOffset() int64 {
+	return r.offset
+}
+
+// FixedWidthOptions controls how a FixedWidthWriter writes rows.
+type FixedWidthOptions struct {
+	NoHeader bool // the first row is data, otherwise it is the header and isn't written
+	Truncate bool // cut values that are too long for their field, otherwise they are an error
+	CRLF     bool // end lines with \r\n
+}
+
+// FixedWidthWriter writes rows as the lines of a fixed-width file, putting each column of the row in the field of the
+// layout at the same position. It has the same Write and Flush as the CSV Writer.
+type FixedWidthWriter struct {
+	w       *bufio.Writer
+	layout  *Layout
+	options FixedWidthOptions
+	header  bool
+	width   int
+}
+
+func NewFixedWidthWriter(w io.Writer, layout *Layout, options FixedWidthOptions) *FixedWidthWriter {
+	return &FixedWidthWriter{w: bufio.NewWriter(w), layout: layout, options: options, header: options.NoHeader, width: layout.width()}
+}
+
+// CheckRow makes sure the row fits the layout, so it can be rejected before it is written.
+func (w *FixedWidthWriter) CheckRow(row []string) error {
+	if len(row) > len(w.layout.Fields) {
+		return fmt.Errorf("row has %d columns but the layout only has %d fields", len(row), len(w.layout.Fields))
+	}
+	if w.options.Truncate {
+		return nil
+	}
+	for i, value := range row {
+		f := w.layout.Fields[i]
+		if utf8.RuneCountInString(value) > f.Len {
+			return fmt.Errorf("value '%s' is longer than the %d characters of field %s", value, f.Len, f.Name)
+		}
+	}
+	return nil
+}
+
+// Write writes a row as a line, skipping the first row when it is the header.
+func (w *FixedWidthWriter) Write(row []string) error {
+	if !w.header {
+		w.header = true
+		return nil
+	}
+	if err := w.CheckRow(row); err != nil {
+		return err
+	}
+
+	line := []rune(strings.Repeat(" ", w.width))
+	for i, f := range w.layout.Fields {
+		var value []rune
+		if i < len(row) {
+			value = []rune(row[i])
+		}
+		if len(value) > f.Len {
+			value = value[:f.Len]
+		}
+		padding := []rune(strings.Repeat(string(f.pad()), f.Len-len(value)))
+		if f.Right {
+			value = append(padding, value...)
+		} else {
+			value = append(value, padding...)
+		}
+		copy(line[f.Start-1:], value)
+	}
+
+	if _, err := w.w.WriteString(string(line)); err != nil {
+		return err
+	}
+	if w.options.CRLF {
+		_, err := w.w.WriteString("\r\n")
+		return err
+	}
+	return w.w.WriteByte('\n')
+}
+
+// Flush writes any buffered lines. Use Error to find out whether it worked.
+func (w *FixedWidthWriter) Flush() {
+	_ = w.w.Flush()
+}
+
+// Error returns any error from a previous Write or Flush.
+func (w *FixedWidthWriter) Error() error {
+	_, err := w.w.Write(nil)
+	return err
+}
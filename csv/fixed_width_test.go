@@ -0,0 +1,163 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testLayout = `name,start,length,align,pad
+id,1,5,right,0
+name,6,8
+amount,15,7,right,
+`
+
+func readTestLayout(t *testing.T) *Layout {
+	t.Helper()
+	layout, err := ReadLayout(strings.NewReader(testLayout))
+	if err != nil {
+		t.Fatalf("layout error = %v", err)
+	}
+	return layout
+}
+
+func TestFixedWidthReader_Read(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  [][]string
+	}{
+		{
+			name:  "padding is removed",
+			input: "00042Ann      1250.00\n00000Bob Ray        7\n",
+			want:  [][]string{{"id", "name", "amount"}, {"42", "Ann", "1250.00"}, {"0", "Bob Ray", "7"}},
+		},
+		{
+			name:  "blank lines are skipped",
+			input: "\n00001Ann            \n   \n",
+			want:  [][]string{{"id", "name", "amount"}, {"1", "Ann", ""}},
+		},
+		{
+			name:  "short lines leave fields empty",
+			input: "short\n00002Bo",
+			want:  [][]string{{"id", "name", "amount"}, {"short", "", ""}, {"2", "Bo", ""}},
+		},
+		{
+			name:  "byte order mark and crlf",
+			input: "\ufeff00003Zoë          1\r\n",
+			want:  [][]string{{"id", "name", "amount"}, {"3", "Zoë", "1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewFixedWidthReader(strings.NewReader(tt.input), readTestLayout(t))
+			var got [][]string
+			for {
+				row, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("read error = %v", err)
+				}
+				got = append(got, row)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+			if r.InputOffset() != int64(len(tt.input)) {
+				t.Errorf("offset = %d, want %d", r.InputOffset(), len(tt.input))
+			}
+		})
+	}
+}
+
+func TestFixedWidthWriter_Write(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     FixedWidthOptions
+		rows        [][]string
+		want        string
+		wantErrText string
+	}{
+		{
+			name: "the header isn't written",
+			rows: [][]string{{"id", "name", "amount"}, {"42", "Ann", "1250.00"}, {"7", "Bob"}},
+			want: "00042Ann      1250.00\n00007Bob             \n",
+		},
+		{
+			name:    "without a header",
+			options: FixedWidthOptions{NoHeader: true, CRLF: true},
+			rows:    [][]string{{"1", "Zoë", ""}},
+			want:    "00001Zoë             \r\n",
+		},
+		{
+			name:    "too long values are truncated",
+			options: FixedWidthOptions{Truncate: true},
+			rows:    [][]string{{"id", "name"}, {"1", "Bartholomew"}},
+			want:    "00001Bartholo        \n",
+		},
+		{
+			name:        "too long values fail",
+			rows:        [][]string{{"id", "name"}, {"1", "Bartholomew"}},
+			wantErrText: "value 'Bartholomew' is longer than the 8 characters of field name",
+		},
+		{
+			name:        "more columns than fields",
+			options:     FixedWidthOptions{Truncate: true},
+			rows:        [][]string{{"a", "b", "c", "d"}, {"1", "1", "1", "1"}},
+			wantErrText: "row has 4 columns but the layout only has 3 fields",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w := NewFixedWidthWriter(&b, readTestLayout(t), tt.options)
+			var err error
+			for _, row := range tt.rows {
+				if err = w.Write(row); err != nil {
+					break
+				}
+			}
+			if tt.wantErrText != "" {
+				if err == nil || err.Error() != tt.wantErrText {
+					t.Errorf("error = %v, want %v", err, tt.wantErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			w.Flush()
+			if got := b.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadLayout(t *testing.T) {
+	tests := []struct {
+		name        string
+		layout      string
+		wantErrText string
+	}{
+		{name: "empty", layout: "", wantErrText: "layout is empty"},
+		{name: "no fields", layout: "name,start,length\n", wantErrText: "layout has no fields"},
+		{name: "missing column", layout: "name,start\nid,1\n", wantErrText: "layout header needs a length column"},
+		{name: "bad start", layout: "name,start,length\nid,0,5\n", wantErrText: "line 2: start of id must be 1 or more, found '0'"},
+		{name: "bad length", layout: "name,start,length\nid,1,x\n", wantErrText: "line 2: length of id must be 1 or more, found 'x'"},
+		{name: "bad align", layout: "name,start,length,align\nid,1,5,center\n", wantErrText: "line 2: align of id must be left or right, found 'center'"},
+		{name: "bad pad", layout: "name,start,length,pad\nid,1,5,00\n", wantErrText: "line 2: pad of id must be a single character, found '00'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadLayout(strings.NewReader(tt.layout))
+			if err == nil || err.Error() != tt.wantErrText {
+				t.Errorf("error = %v, want %v", err, tt.wantErrText)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package csv
+
+import "fmt"
+
+// header keeps the header row for the writers that name the columns with it, like the keys of JSON objects. The first
+// row written is the header unless there isn't one, then the columns are named "column 1", "column 2" and so on.
+type header struct {
+	names []string // nil until the header has been seen
+}
+
+func newHeader(noHeader bool) header {
+	if noHeader {
+		return header{names: []string{}}
+	}
+	return header{}
+}
+
+// take keeps the row as the header if it is the first row, and reports whether it was.
+func (h *header) take(row []string) bool {
+	if h.names != nil {
+		return false
+	}
+	h.names = append([]string{}, row...)
+	return true
+}
+
+// name returns the name of the column at position i, starting at 0.
+func (h *header) name(i int) string {
+	if i < len(h.names) {
+		return h.names[i]
+	}
+	return fmt.Sprintf("column %d", i+1)
+}
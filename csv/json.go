@@ -0,0 +1,129 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// JSONOptions controls how a JSONWriter writes rows.
+type JSONOptions struct {
+	NDJSON   bool              // write one object per line instead of an array of objects
+	NoHeader bool              // the first row is data, the keys are "column 1", "column 2" and so on
+	Types    map[int]ValueType // value types by column number starting at 1, other columns are strings
+}
+
+// JSONWriter writes rows as JSON objects, keyed by the header row. It has the same Write and Flush as the CSV
+// Writer, and must be closed to finish the array of objects.
+type JSONWriter struct {
+	w       *bufio.Writer
+	options JSONOptions
+	header  header
+	rows    int
+	err     error
+}
+
+func NewJSONWriter(w io.Writer, options JSONOptions) *JSONWriter {
+	return &JSONWriter{w: bufio.NewWriter(w), options: options, header: newHeader(options.NoHeader)}
+}
+
+// Write keeps the first row as the keys, unless there is no header, and writes every other row as an object.
+func (w *JSONWriter) Write(row []string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.header.take(row) {
+		return nil
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, value := range row {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.Write(marshalString(w.header.name(i)))
+		b.WriteByte(':')
+		encoded, err := w.encode(i, value)
+		if err != nil {
+			return fmt.Errorf("column %d: %v", i+1, err)
+		}
+		b.Write(encoded)
+	}
+	b.WriteByte('}')
+
+	switch {
+	case w.options.NDJSON:
+		b.WriteByte('\n')
+	case w.rows == 0:
+		_, w.err = w.w.WriteString("[\n")
+	default:
+		_, w.err = w.w.WriteString(",\n")
+	}
+	if w.err == nil {
+		_, w.err = w.w.Write(b.Bytes())
+	}
+	w.rows++
+	return w.err
+}
+
+// encode writes the value as its column's type. Empty numbers and bools are null.
+func (w *JSONWriter) encode(i int, value string) ([]byte, error) {
+	valueType := w.options.Types[i+1]
+	if valueType == String {
+		return marshalString(value), nil
+	}
+	if err := CheckValue(valueType, value); err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return []byte("null"), nil
+	}
+	if valueType == Bool {
+		b, _ := strconv.ParseBool(value)
+		return []byte(strconv.FormatBool(b)), nil
+	}
+	// numbers that are already valid JSON are kept as they are, others like +1 or .5 are reformatted
+	if json.Valid([]byte(value)) {
+		return []byte(value), nil
+	}
+	n, _ := strconv.ParseFloat(value, 64)
+	return []byte(strconv.FormatFloat(n, 'f', -1, 64)), nil
+}
+
+// marshalString encodes a JSON string without escaping HTML characters.
+func marshalString(s string) []byte {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return bytes.TrimRight(b.Bytes(), "\n")
+}
+
+// Flush writes any buffered rows.
+func (w *JSONWriter) Flush() {
+	if err := w.w.Flush(); err != nil && w.err == nil {
+		w.err = err
+	}
+}
+
+// Error returns any error from a previous Write, Flush or Close.
+func (w *JSONWriter) Error() error {
+	return w.err
+}
+
+// Close ends the array of objects and flushes the output. It does not close the underlying writer.
+func (w *JSONWriter) Close() error {
+	if w.err == nil && !w.options.NDJSON {
+		if w.rows == 0 {
+			_, w.err = w.w.WriteString("[]\n")
+		} else {
+			_, w.err = w.w.WriteString("\n]\n")
+		}
+	}
+	w.Flush()
+	return w.err
+}
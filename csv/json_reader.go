@@ -0,0 +1,311 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// JSONReader reads JSON objects as rows, either one object per line as in NDJSON, or a single array of objects. The
+// first row is a header of field paths and every other row has the values of those fields. Nested objects are
+// flattened with dotted paths, so {"address": {"city": "Denver"}} has the field address.city.
+//
+// A mistake in a single object is a *csv.ParseError from encoding/csv with the line it starts on, so the object can
+// be skipped. Broken JSON can only be skipped in NDJSON, a broken array can't be read any further.
+type JSONReader struct {
+	r       *bufio.Reader
+	lines   *lineCounter
+	d       *json.Decoder // reads the array, nil for NDJSON
+	skipped int64         // bytes before the array
+	offset  int64         // bytes of NDJSON read
+	done    bool          // the end of the array was read
+	line    int           // line the last object started on
+	fields  []string
+	strict  bool // the fields are those of the first object, and other objects can't have more
+	started bool
+	header  bool
+	pending []string // the first row, read early to find the fields when they weren't given
+}
+
+// NewJSONReader returns a JSONReader for the given field paths. Without fields, the fields of the first object are
+// used in the order they appear, and a later object with a field the first one didn't have is an error. Fields
+// missing from an object are empty.
+func NewJSONReader(r io.Reader, fields []string) *JSONReader {
+	lines := &lineCounter{r: r}
+	return &JSONReader{r: bufio.NewReader(lines), lines: lines, fields: fields, strict: fields == nil}
+}
+
+// Read returns the header of field paths first, then the values of each object.
+func (r *JSONReader) Read() ([]string, error) {
+	if !r.started {
+		r.started = true
+		if err := r.start(); err != nil {
+			return nil, err
+		}
+		if r.fields == nil {
+			values, order, err := r.next()
+			if err != nil {
+				// there is no header without any objects
+				r.header = true
+				return nil, err
+			}
+			r.fields = order
+			r.pending = r.row(values)
+		}
+	}
+
+	if !r.header {
+		r.header = true
+		return append([]string{}, r.fields...), nil
+	}
+	if r.pending != nil {
+		row := r.pending
+		r.pending = nil
+		return row, nil
+	}
+
+	values, order, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	if r.strict {
+		if err := r.checkFields(order); err != nil {
+			return nil, err
+		}
+	}
+	return r.row(values), nil
+}
+
+// start skips a byte order mark and finds out whether the objects are in an array.
+func (r *JSONReader) start() error {
+	if bom, err := r.r.Peek(3); err == nil && bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		_, _ = r.r.Discard(3)
+		r.skipped, r.offset = 3, 3
+	}
+	for {
+		b, err := r.r.Peek(1)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = r.r.Discard(1)
+			r.skipped++
+			r.offset++
+			continue
+		case '[':
+			r.d = json.NewDecoder(r.r)
+			r.d.UseNumber()
+			_, err = r.d.Token()
+			return err
+		}
+		return nil
+	}
+}
+
+// next reads the next object and flattens it, returning the values by path and the paths in the order they were
+// found.
+func (r *JSONReader) next() (map[string]string, []string, error) {
+	raw, line, err := r.nextRecord()
+	if err != nil {
+		return nil, nil, err
+	}
+	r.line = line
+	if raw[0] != '{' {
+		return nil, nil, recordError(r.line, 1, errors.New("not an object"))
+	}
+
+	values := make(map[string]string)
+	var order []string
+	if err := flatten(raw, "", values, &order); err != nil {
+		return nil, nil, recordError(r.line, 1, err)
+	}
+	return values, order, nil
+}
+
+// nextRecord returns the next value in the array, or on the next line that isn't blank, and the line it starts on.
+func (r *JSONReader) nextRecord() (json.RawMessage, int, error) {
+	if r.done {
+		return nil, 0, io.EOF
+	}
+	if r.d != nil {
+		if !r.d.More() {
+			if _, err := r.d.Token(); err != nil {
+				return nil, 0, err
+			}
+			r.done = true
+			return nil, 0, io.EOF
+		}
+		var raw json.RawMessage
+		if err := r.d.Decode(&raw); err != nil {
+			// the array can't be read past broken JSON, so it isn't a mistake in a single object
+			position := r.lines.read - 1
+			var syntaxError *json.SyntaxError
+			if errors.As(err, &syntaxError) {
+				position = r.skipped + syntaxError.Offset - 1
+			}
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, 0, fmt.Errorf("line %d: %v", r.lines.lineAt(position), err)
+		}
+		return raw, r.lines.lineAt(r.InputOffset() - int64(len(raw))), nil
+	}
+
+	for {
+		b, err := r.r.ReadBytes('\n')
+		if len(b) == 0 && err != nil {
+			return nil, 0, err
+		}
+		line := r.lines.lineAt(r.offset)
+		r.offset += int64(len(b))
+		b = bytes.TrimSpace(b)
+		if len(b) == 0 {
+			continue
+		}
+		var raw json.RawMessage
+		if err := json.Unmarshal(b, &raw); err != nil {
+			column := 1
+			var syntaxError *json.SyntaxError
+			if errors.As(err, &syntaxError) {
+				column = int(syntaxError.Offset)
+			}
+			return nil, 0, recordError(line, column, err)
+		}
+		return raw, line, nil
+	}
+}
+
+// checkFields makes sure an object only has the fields of the first one, as they are the columns of every row. Input
+// with objects that have different fields needs them listed with @fields.
+func (r *JSONReader) checkFields(order []string) error {
+	for _, path := range order {
+		found := false
+		for _, field := range r.fields {
+			if field == path {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return recordError(r.line, 1, fmt.Errorf("field %s is not in the first object, list every field with @fields when the objects differ", path))
+		}
+	}
+	return nil
+}
+
+func (r *JSONReader) row(values map[string]string) []string {
+	row := make([]string, len(r.fields))
+	for i, field := range r.fields {
+		row[i] = values[field]
+	}
+	return row
+}
+
+// flatten adds the fields of an object to values, keyed by their path. Nested objects are flattened too, and are
+// also kept whole as JSON under their own path so they can be asked for by name. Arrays are kept as JSON, strings
+// are unquoted and null is empty.
+func flatten(object json.RawMessage, prefix string, values map[string]string, order *[]string) error {
+	d := json.NewDecoder(bytes.NewReader(object))
+	d.UseNumber()
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	for d.More() {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return errors.New("object key is not a string")
+		}
+		path := prefix + key
+		_, seen := values[path]
+
+		var value json.RawMessage
+		if err := d.Decode(&value); err != nil {
+			return err
+		}
+		switch value[0] {
+		case '{':
+			if err := flatten(value, path+".", values, order); err != nil {
+				return err
+			}
+			values[path] = compact(value)
+			continue
+		case '"':
+			var s string
+			if err := json.Unmarshal(value, &s); err != nil {
+				return err
+			}
+			values[path] = s
+		case 'n':
+			values[path] = ""
+		case '[':
+			values[path] = compact(value)
+		default:
+			values[path] = string(value)
+		}
+		if !seen {
+			*order = append(*order, path)
+		}
+	}
+	return nil
+}
+
+func compact(value json.RawMessage) string {
+	var b bytes.Buffer
+	if err := json.Compact(&b, value); err != nil {
+		return string(value)
+	}
+	return b.String()
+}
+
+// InputOffset returns how many bytes have been read.
+func (r *JSONReader) InputOffset() int64 {
+	if r.d != nil {
+		return r.skipped + r.d.InputOffset()
+	}
+	return r.offset
+}
+
+func recordError(line int, column int, err error) error {
+	return &csv.ParseError{StartLine: line, Line: line, Column: column, Err: err}
+}
+
+// lineCounter keeps the positions of the newlines that have been read, so the line at a position can be found once
+// the reader has read ahead of it. The positions must be asked for in order.
+type lineCounter struct {
+	r        io.Reader
+	read     int64
+	newlines []int64 // positions of newlines past the last position asked for
+	line     int     // line of the last position asked for, starting at 0
+}
+
+func (c *lineCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			c.newlines = append(c.newlines, c.read+int64(i))
+		}
+	}
+	c.read += int64(n)
+	return n, err
+}
+
+// lineAt returns the line of the byte at the position, starting at 1.
+func (c *lineCounter) lineAt(position int64) int {
+	for len(c.newlines) > 0 && c.newlines[0] < position {
+		c.newlines = c.newlines[1:]
+		c.line++
+	}
+	return c.line + 1
+}
@@ -0,0 +1,141 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readJSON reads every row, and the line of each row that was a ParseError.
+func readJSON(t *testing.T, input string, fields []string) ([][]string, []int, error) {
+	t.Helper()
+	r := NewJSONReader(strings.NewReader(input), fields)
+	var rows [][]string
+	var badLines []int
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return rows, badLines, nil
+		}
+		var parseError *csv.ParseError
+		if errors.As(err, &parseError) {
+			badLines = append(badLines, parseError.Line)
+			continue
+		}
+		if err != nil {
+			return rows, badLines, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestJSONReader_Read(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		fields       []string
+		want         [][]string
+		wantBadLines []int
+		wantErrText  string
+	}{
+		{
+			name:  "ndjson",
+			input: "\ufeff{\"a\":1,\"b\":{\"c\":\"x\"}}\n\n{\"b\":{\"c\":\"y\"}}\n",
+			want:  [][]string{{"a", "b.c"}, {"1", "x"}, {"", "y"}},
+		},
+		{
+			name:         "ndjson skips broken lines",
+			input:        "{\"a\":1}\n{\"a\":\n[2]\n{\"a\":3}",
+			want:         [][]string{{"a"}, {"1"}, {"3"}},
+			wantBadLines: []int{2, 3},
+		},
+		{
+			name:  "values of other types",
+			input: "[ {\"n\": 1.50, \"ok\": true, \"none\": null, \"tags\": [\"a\", \"b\"]},\n{\"n\": 2} ]\n",
+			want:  [][]string{{"n", "ok", "none", "tags"}, {"1.50", "true", "", "[\"a\",\"b\"]"}, {"2", "", "", ""}},
+		},
+		{
+			name:  "empty input",
+			input: "",
+		},
+		{
+			name:         "fields that aren't in the first object",
+			input:        "{\"a\":1}\n{\"a\":2,\"b\":3}\n{\"a\":4,\"c\":{\"d\":5}}\n{\"a\":6}\n",
+			want:         [][]string{{"a"}, {"1"}, {"6"}},
+			wantBadLines: []int{2, 3},
+		},
+		{
+			name:   "declared fields leave out the others",
+			input:  "{\"a\":1}\n{\"a\":2,\"b\":3}\n",
+			fields: []string{"b"},
+			want:   [][]string{{"b"}, {""}, {"3"}},
+		},
+		{
+			name:   "declared objects are kept as json",
+			input:  "{\"address\":{\"zip\":\"80202\"},\"id\":7}\n",
+			fields: []string{"id", "address", "address.zip"},
+			want:   [][]string{{"id", "address", "address.zip"}, {"7", "{\"zip\":\"80202\"}", "80202"}},
+		},
+		{
+			name:         "array over several lines",
+			input:        "\n[\n  {\"a\": 1},\n  {\n    \"a\": 2,\n    \"b\": 3\n  },\n  \"x\"\n]\n",
+			want:         [][]string{{"a"}, {"1"}},
+			wantBadLines: []int{4, 8},
+		},
+		{
+			name:        "broken array",
+			input:       "[{\"a\":1},\n{\"a\":}]",
+			want:        [][]string{{"a"}, {"1"}},
+			wantErrText: "line 2: invalid character '}' after array element",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, badLines, err := readJSON(t, tt.input, tt.fields)
+			if tt.wantErrText != "" {
+				if err == nil || err.Error() != tt.wantErrText {
+					t.Errorf("error = %v, want %v", err, tt.wantErrText)
+				}
+			} else if err != nil {
+				t.Fatalf("read error = %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("rows = %q, want %q", rows, tt.want)
+			}
+			if !reflect.DeepEqual(badLines, tt.wantBadLines) {
+				t.Errorf("bad lines = %v, want %v", badLines, tt.wantBadLines)
+			}
+		})
+	}
+}
+
+func TestJSONReader_ParseError(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantErrText string
+	}{
+		{name: "only objects", input: "{\"n\":1}\n[1]\n", wantErrText: "parse error on line 2, column 1: not an object"},
+		{name: "broken json", input: "{\"n\":1}\n{\"n\":\n", wantErrText: "parse error on line 2, column 5: unexpected end of JSON input"},
+		{
+			name:        "a field that isn't in the first object",
+			input:       "{\"n\":1}\n{\"n\":2,\"m\":3}\n",
+			wantErrText: "parse error on line 2, column 1: field m is not in the first object, list every field with @fields when the objects differ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewJSONReader(strings.NewReader(tt.input), nil)
+			var err error
+			for err == nil {
+				_, err = r.Read()
+			}
+			if err.Error() != tt.wantErrText {
+				t.Errorf("error = %v, want %v", err, tt.wantErrText)
+			}
+		})
+	}
+}
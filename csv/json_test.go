@@ -0,0 +1,96 @@
+package csv
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestJSONWriter_Write(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     JSONOptions
+		rows        [][]string
+		want        string
+		wantErrText string
+	}{
+		{
+			name: "keys come from the header",
+			rows: [][]string{{"id", "full name"}, {"1", "Ann <Lee>"}, {"2", "Bob Ray \"Jr\""}},
+			want: "[\n{\"id\":\"1\",\"full name\":\"Ann <Lee>\"},\n{\"id\":\"2\",\"full name\":\"Bob Ray \\\"Jr\\\"\"}\n]\n",
+		},
+		{
+			name:    "ndjson",
+			options: JSONOptions{NDJSON: true},
+			rows:    [][]string{{"id", "name"}, {"1", "Ann"}, {"2", "Bob"}},
+			want:    "{\"id\":\"1\",\"name\":\"Ann\"}\n{\"id\":\"2\",\"name\":\"Bob\"}\n",
+		},
+		{
+			name:    "without a header",
+			options: JSONOptions{NoHeader: true},
+			rows:    [][]string{{"1", "Ann"}},
+			want:    "[\n{\"column 1\":\"1\",\"column 2\":\"Ann\"}\n]\n",
+		},
+		{
+			name: "no rows",
+			rows: [][]string{{"id"}},
+			want: "[]\n",
+		},
+		{
+			name:    "typed values",
+			options: JSONOptions{NDJSON: true, Types: map[int]ValueType{1: Number, 2: Bool, 3: String}},
+			rows:    [][]string{{"n", "b", "s"}, {"1.50", "T", "7"}, {"+1", "false", ""}, {"", "", "x"}, {".5", "1", ""}},
+			want:    "{\"n\":1.50,\"b\":true,\"s\":\"7\"}\n{\"n\":1,\"b\":false,\"s\":\"\"}\n{\"n\":null,\"b\":null,\"s\":\"x\"}\n{\"n\":0.5,\"b\":true,\"s\":\"\"}\n",
+		},
+		{
+			name:        "numbers must be numbers",
+			options:     JSONOptions{Types: map[int]ValueType{1: Number}},
+			rows:        [][]string{{"n"}, {"12"}, {"abc"}},
+			wantErrText: "column 1: value 'abc' is not a number",
+		},
+		{
+			name:        "bools must be bools",
+			options:     JSONOptions{Types: map[int]ValueType{2: Bool}},
+			rows:        [][]string{{"n", "b"}, {"1", "yes"}},
+			wantErrText: "column 2: value 'yes' is not true or false",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w := NewJSONWriter(&b, tt.options)
+			var err error
+			for _, row := range tt.rows {
+				if err = w.Write(row); err != nil {
+					break
+				}
+			}
+			if tt.wantErrText != "" {
+				if err == nil || err.Error() != tt.wantErrText {
+					t.Errorf("error = %v, want %v", err, tt.wantErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("close error = %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseValueType(t *testing.T) {
+	for name, want := range map[string]ValueType{"string": String, "Number": Number, "BOOL": Bool} {
+		if got, err := ParseValueType(name); err != nil || got != want {
+			t.Errorf("ParseValueType(%s) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	want := "unrecognized type 'date', expected string, number or bool"
+	if _, err := ParseValueType("date"); err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,133 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Layout describes the fields of a fixed-width file.
+type Layout struct {
+	Fields []LayoutField
+}
+
+// LayoutField is a single field of a fixed-width line. Positions and lengths count characters, not bytes.
+type LayoutField struct {
+	Name  string
+	Start int  // position of the first character, starting at 1
+	Len   int  // number of characters
+	Right bool // values are right aligned, with the padding before them
+	Pad   rune // character filling the rest of the field, a space if not set
+}
+
+func (f LayoutField) pad() rune {
+	if f.Pad == 0 {
+		return ' '
+	}
+	return f.Pad
+}
+
+// width is how many characters a line of the layout has.
+func (l *Layout) width() int {
+	width := 0
+	for _, f := range l.Fields {
+		if end := f.Start + f.Len - 1; end > width {
+			width = end
+		}
+	}
+	return width
+}
+
+// LoadLayout reads a layout definition from a file, see ReadLayout.
+func LoadLayout(path string) (*Layout, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	layout, err := ReadLayout(f)
+	if err != nil {
+		return nil, fmt.Errorf("layout %s: %v", path, err)
+	}
+	return layout, nil
+}
+
+// ReadLayout reads a layout definition, which is a CSV file with a row for each field. The header names the columns:
+// name, start and length are required, align (left or right, left if empty) and pad (a space if empty) are optional.
+func ReadLayout(r io.Reader) (*Layout, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("layout is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "start", "length"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("layout header needs a %s column", required)
+		}
+	}
+
+	layout := &Layout{}
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		field := LayoutField{Name: value("name")}
+		if field.Name == "" {
+			return nil, fmt.Errorf("line %d: field needs a name", line)
+		}
+		if field.Start, err = strconv.Atoi(value("start")); err != nil || field.Start < 1 {
+			return nil, fmt.Errorf("line %d: start of %s must be 1 or more, found '%s'", line, field.Name, value("start"))
+		}
+		if field.Len, err = strconv.Atoi(value("length")); err != nil || field.Len < 1 {
+			return nil, fmt.Errorf("line %d: length of %s must be 1 or more, found '%s'", line, field.Name, value("length"))
+		}
+		switch strings.ToLower(value("align")) {
+		case "", "left":
+		case "right":
+			field.Right = true
+		default:
+			return nil, fmt.Errorf("line %d: align of %s must be left or right, found '%s'", line, field.Name, value("align"))
+		}
+		// the pad isn't trimmed, so it can be a space
+		if i, ok := columns["pad"]; ok && i < len(row) && row[i] != "" {
+			pad, size := utf8.DecodeRuneInString(row[i])
+			if size != len(row[i]) {
+				return nil, fmt.Errorf("line %d: pad of %s must be a single character, found '%s'", line, field.Name, row[i])
+			}
+			field.Pad = pad
+		}
+		layout.Fields = append(layout.Fields, field)
+	}
+
+	if len(layout.Fields) == 0 {
+		return nil, fmt.Errorf("layout has no fields")
+	}
+	return layout, nil
+}
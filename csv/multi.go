@@ -0,0 +1,189 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RowReader is anything that reads rows, like a Reader, JSONReader or FixedWidthReader.
+type RowReader interface {
+	Read() ([]string, error)
+}
+
+// OpenFunc opens a file for a MultiReader, returning its reader and a function that closes it.
+type OpenFunc func(path string) (RowReader, func() error, error)
+
+// MultiReader reads several files one after another as if they were a single file. When the files have headers,
+// only the header of the first file is read, the headers of the others are skipped. If they are aligned, the columns
+// of the other files are moved to match the first file by their header names.
+type MultiReader struct {
+	paths  []string
+	open   OpenFunc
+	header bool
+	align  bool
+
+	file     int // index of the file being read, or -1 before the first
+	reader   RowReader
+	close    func() error
+	first    []string       // header of the first file
+	firstAt  string         // path of the file the header came from
+	columns  map[string]int // positions of the first file's columns by header name
+	mapping  []int          // position in the first file for each column of the current file, nil to leave alone
+	offset   int64          // bytes read from the files before the current one
+	finished bool
+}
+
+// NewMultiReader returns a MultiReader for the files. Header says whether each file starts with a header row, and
+// align moves the columns of each file to match the header of the first.
+func NewMultiReader(paths []string, open OpenFunc, header bool, align bool) *MultiReader {
+	return &MultiReader{paths: paths, open: open, header: header, align: align, file: -1}
+}
+
+// Read returns the next row, moving on to the next file at the end of each one.
+func (m *MultiReader) Read() ([]string, error) {
+	for {
+		if m.reader == nil {
+			if err := m.next(); err != nil {
+				return nil, err
+			}
+			// the first file with anything in it supplies the header, the headers of the rest are skipped
+			if m.header && m.first != nil {
+				err := m.skipHeader()
+				if err == io.EOF {
+					if err := m.done(); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		row, err := m.reader.Read()
+		if err == io.EOF {
+			if err := m.done(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return row, fmt.Errorf("%s: %w", m.Filename(), err)
+		}
+
+		if m.header && m.first == nil {
+			m.setFirstHeader(row)
+		}
+		if m.mapping != nil {
+			row = m.aligned(row)
+		}
+		return row, nil
+	}
+}
+
+// next opens the next file, or returns io.EOF when there are no more.
+func (m *MultiReader) next() error {
+	if m.finished || m.file+1 >= len(m.paths) {
+		m.finished = true
+		return io.EOF
+	}
+	m.file++
+	reader, closeFile, err := m.open(m.paths[m.file])
+	if err != nil {
+		return fmt.Errorf("%s: %w", m.paths[m.file], err)
+	}
+	m.reader = reader
+	m.close = closeFile
+	m.mapping = nil
+	return nil
+}
+
+// done closes the current file.
+func (m *MultiReader) done() error {
+	if offset := readerOffset(m.reader); offset > 0 {
+		m.offset += offset
+	}
+	m.reader = nil
+	if m.close == nil {
+		return nil
+	}
+	err := m.close()
+	m.close = nil
+	return err
+}
+
+func (m *MultiReader) setFirstHeader(row []string) {
+	m.first = append([]string(nil), row...)
+	m.firstAt = m.Filename()
+	m.columns = make(map[string]int)
+	for i, name := range m.first {
+		m.columns[headerName(name)] = i
+	}
+}
+
+// skipHeader reads the header of a file after the first, working out where its columns go when aligning.
+func (m *MultiReader) skipHeader() error {
+	row, err := m.reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return err
+		}
+		return fmt.Errorf("%s: %w", m.Filename(), err)
+	}
+	if !m.align {
+		return nil
+	}
+
+	m.mapping = make([]int, len(row))
+	for i, name := range row {
+		column, ok := m.columns[headerName(name)]
+		if !ok {
+			return fmt.Errorf("%s: column %s is not in the header of %s", m.Filename(), name, m.firstAt)
+		}
+		m.mapping[i] = column
+	}
+	return nil
+}
+
+func (m *MultiReader) aligned(row []string) []string {
+	out := make([]string, len(m.first))
+	for i, value := range row {
+		if i < len(m.mapping) {
+			out[m.mapping[i]] = value
+		}
+	}
+	return out
+}
+
+func headerName(name string) string {
+	return strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+}
+
+// Filename returns the path of the file being read.
+func (m *MultiReader) Filename() string {
+	if m.file < 0 || m.file >= len(m.paths) {
+		return ""
+	}
+	return m.paths[m.file]
+}
+
+// InputOffset returns how many bytes have been read from all the files, or -1 if the readers can't say.
+func (m *MultiReader) InputOffset() int64 {
+	if m.reader == nil {
+		return m.offset
+	}
+	offset := readerOffset(m.reader)
+	if offset < 0 {
+		return -1
+	}
+	return m.offset + offset
+}
+
+func readerOffset(reader RowReader) int64 {
+	if r, ok := reader.(interface{ InputOffset() int64 }); ok {
+		return r.InputOffset()
+	}
+	return -1
+}
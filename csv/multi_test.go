@@ -0,0 +1,136 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMultiReader_Read(t *testing.T) {
+	files := map[string]string{
+		"a.csv":     "id,name\n1,Ann\n2,Bob\n",
+		"b.csv":     "id,name\n3,Cal\n",
+		"empty.csv": "",
+		"moved.csv": "\ufeffname , id\nDee,4\n",
+		"short.csv": "id\n5\n",
+		"extra.csv": "id,name,age\n6,Eve,40\n",
+		"nohead":    "7,Fay\n",
+		"broken":    "id,name\n8\"\n",
+	}
+
+	tests := []struct {
+		name          string
+		paths         []string
+		header        bool
+		align         bool
+		want          [][]string
+		wantFilenames []string
+		wantErrText   string
+	}{
+		{
+			name:          "only the first header is kept",
+			paths:         []string{"a.csv", "b.csv"},
+			header:        true,
+			want:          [][]string{{"id", "name"}, {"1", "Ann"}, {"2", "Bob"}, {"3", "Cal"}},
+			wantFilenames: []string{"a.csv", "a.csv", "a.csv", "b.csv"},
+		},
+		{
+			name:          "empty files are skipped",
+			paths:         []string{"empty.csv", "a.csv", "empty.csv", "b.csv", "empty.csv"},
+			header:        true,
+			want:          [][]string{{"id", "name"}, {"1", "Ann"}, {"2", "Bob"}, {"3", "Cal"}},
+			wantFilenames: []string{"a.csv", "a.csv", "a.csv", "b.csv"},
+		},
+		{
+			name:          "columns are aligned by header",
+			paths:         []string{"a.csv", "moved.csv", "short.csv"},
+			header:        true,
+			align:         true,
+			want:          [][]string{{"id", "name"}, {"1", "Ann"}, {"2", "Bob"}, {"4", "Dee"}, {"5", ""}},
+			wantFilenames: []string{"a.csv", "a.csv", "a.csv", "moved.csv", "short.csv"},
+		},
+		{
+			name:          "columns are left alone without aligning",
+			paths:         []string{"a.csv", "moved.csv"},
+			header:        true,
+			want:          [][]string{{"id", "name"}, {"1", "Ann"}, {"2", "Bob"}, {"Dee", "4"}},
+			wantFilenames: []string{"a.csv", "a.csv", "a.csv", "moved.csv"},
+		},
+		{
+			name:          "files without headers are joined",
+			paths:         []string{"nohead", "b.csv"},
+			want:          [][]string{{"7", "Fay"}, {"id", "name"}, {"3", "Cal"}},
+			wantFilenames: []string{"nohead", "b.csv", "b.csv"},
+		},
+		{
+			name:          "a column missing from the first header fails",
+			paths:         []string{"a.csv", "extra.csv"},
+			header:        true,
+			align:         true,
+			want:          [][]string{{"id", "name"}, {"1", "Ann"}, {"2", "Bob"}},
+			wantFilenames: []string{"a.csv", "a.csv", "a.csv"},
+			wantErrText:   "extra.csv: column age is not in the header of a.csv",
+		},
+		{
+			name:          "a file that can't be opened fails",
+			paths:         []string{"a.csv", "missing.csv"},
+			header:        true,
+			want:          [][]string{{"id", "name"}, {"1", "Ann"}, {"2", "Bob"}},
+			wantFilenames: []string{"a.csv", "a.csv", "a.csv"},
+			wantErrText:   "missing.csv: no such file",
+		},
+		{
+			name:          "errors name the file",
+			paths:         []string{"a.csv", "broken"},
+			header:        true,
+			want:          [][]string{{"id", "name"}, {"1", "Ann"}, {"2", "Bob"}},
+			wantFilenames: []string{"a.csv", "a.csv", "a.csv"},
+			wantErrText:   "broken: parse error on line 2, column 2: bare \" in non-quoted-field",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opened, closed := 0, 0
+			open := func(path string) (RowReader, func() error, error) {
+				contents, ok := files[path]
+				if !ok {
+					return nil, nil, fmt.Errorf("no such file")
+				}
+				opened++
+				reader, err := NewReader(strings.NewReader(contents), Dialect{})
+				return reader, func() error { closed++; return nil }, err
+			}
+
+			m := NewMultiReader(tt.paths, open, tt.header, tt.align)
+			var rows [][]string
+			var filenames []string
+			var err error
+			for {
+				var row []string
+				row, err = m.Read()
+				if err != nil {
+					break
+				}
+				rows = append(rows, row)
+				filenames = append(filenames, m.Filename())
+			}
+			if tt.wantErrText == "" && err != io.EOF {
+				t.Fatalf("read error = %v", err)
+			}
+			if tt.wantErrText != "" && (err == nil || err.Error() != tt.wantErrText) {
+				t.Errorf("error = %v, want %v", err, tt.wantErrText)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("rows = %q, want %q", rows, tt.want)
+			}
+			if !reflect.DeepEqual(filenames, tt.wantFilenames) {
+				t.Errorf("filenames = %q, want %q", filenames, tt.wantFilenames)
+			}
+			if tt.wantErrText == "" && closed != opened {
+				t.Errorf("closed %d files, want %d", closed, opened)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// Reader reads rows of a CSV file written in a Dialect.
+type Reader struct {
+	r *csv.Reader
+	// quote is swapped with " in the input so the standard reader can parse it, and swapped back in the fields.
+	// It is 0 when the dialect quotes with ".
+	quote byte
+}
+
+// NewReader returns a Reader for the dialect, or an error if the dialect is not valid.
+func NewReader(r io.Reader, dialect Dialect) (*Reader, error) {
+	if err := dialect.Validate(); err != nil {
+		return nil, err
+	}
+
+	reader := &Reader{}
+	if q := dialect.quote(); q != '"' {
+		reader.quote = byte(q)
+		r = quoteSwapper{r: r, quote: reader.quote}
+	}
+
+	reader.r = csv.NewReader(r)
+	reader.r.Comma = reader.swap(dialect.delimiter())
+	reader.r.Comment = reader.swap(dialect.Comment)
+	reader.r.LazyQuotes = dialect.LazyQuotes
+	return reader, nil
+}
+
+// swap exchanges the dialect's quote with " and leaves every other character alone.
+func (r *Reader) swap(c rune) rune {
+	switch {
+	case r.quote == 0:
+		return c
+	case c == '"':
+		return rune(r.quote)
+	case c == rune(r.quote):
+		return '"'
+	}
+	return c
+}
+
+// Read returns the next row. Errors for a malformed row are *csv.ParseError from encoding/csv.
+func (r *Reader) Read() ([]string, error) {
+	row, err := r.r.Read()
+	if r.quote != 0 {
+		for i, field := range row {
+			b := []byte(field)
+			swapQuotes(b, r.quote)
+			row[i] = string(b)
+		}
+	}
+	return row, err
+}
+
+// AllowAnyFieldCount lets rows have a different number of fields from the first one.
+func (r *Reader) AllowAnyFieldCount() {
+	r.r.FieldsPerRecord = -1
+}
+
+// InputOffset returns how many bytes have been read, or -1 if encoding/csv is too old to say.
+func (r *Reader) InputOffset() int64 {
+	if o, ok := interface{}(r.r).(interface{ InputOffset() int64 }); ok {
+		return o.InputOffset()
+	}
+	return -1
+}
+
+// quoteSwapper exchanges a single byte quote with " as the input is read. Both are ASCII, so a byte that matches
+// can never be part of a multi-byte character.
+type quoteSwapper struct {
+	r     io.Reader
+	quote byte
+}
+
+func (s quoteSwapper) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	swapQuotes(p[:n], s.quote)
+	return n, err
+}
+
+func swapQuotes(b []byte, quote byte) {
+	for i, c := range b {
+		if c == '"' {
+			b[i] = quote
+		} else if c == quote {
+			b[i] = '"'
+		}
+	}
+}
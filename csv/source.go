@@ -1,25 +1,138 @@
 package csv
 
 import (
-	"encoding/csv"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 )
 
-func NewCsvSource(filename string) (*csv.Reader, func() error, error) {
-	file, err := os.Open(filename)
+// StdPath is the path that means stdin when reading and stdout when writing.
+const StdPath = "-"
+
+// Input is a file being read, or stdin. Gzipped input is decompressed as it is read.
+type Input struct {
+	io.Reader
+	Size    int64 // size of the file, or 0 when it isn't known because it is stdin or gzipped
+	closers []func() error
+}
+
+// OpenInput opens a file to read, or stdin when the path is -. Gzipped input is recognized by its contents, so it
+// doesn't need to end in .gz.
+func OpenInput(path string) (*Input, error) {
+	input := &Input{}
+	file := os.Stdin
+	if path != StdPath {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		file = f
+		input.closers = append(input.closers, f.Close)
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			input.Size = info.Size()
+		}
+	}
+
+	buffered := bufio.NewReader(file)
+	input.Reader = buffered
+	if magic, err := buffered.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			_ = input.Close()
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		input.Reader = gz
+		input.Size = 0
+		input.closers = append([]func() error{gz.Close}, input.closers...)
+	}
+	return input, nil
+}
+
+// Close closes the file, stdin is left open.
+func (i *Input) Close() error {
+	var err error
+	for _, c := range i.closers {
+		if cerr := c(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	i.closers = nil
+	return err
+}
+
+// Output is a file being written, or stdout. Output to a file ending in .gz is gzipped.
+type Output struct {
+	io.Writer
+	closers []func() error
+}
+
+// CreateOutput creates a file to write, or writes to stdout when the path is -. An existing file is only replaced
+// when overwrite is true, otherwise the error wraps os.ErrExist.
+func CreateOutput(path string, overwrite bool) (*Output, error) {
+	if path == StdPath {
+		return &Output{Writer: os.Stdout}, nil
+	}
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return nil, fmt.Errorf("%s: %w", path, os.ErrExist)
+	}
+	f, err := os.Create(path)
 	if err != nil {
+		return nil, err
+	}
+
+	output := &Output{Writer: f, closers: []func() error{f.Close}}
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gz := gzip.NewWriter(f)
+		output.Writer = gz
+		output.closers = append([]func() error{gz.Close}, output.closers...)
+	}
+	return output, nil
+}
+
+// Close finishes the gzip stream, if there is one, and closes the file. Stdout is left open. Flush any writer using
+// the output first.
+func (o *Output) Close() error {
+	var err error
+	for _, c := range o.closers {
+		if cerr := c(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	o.closers = nil
+	return err
+}
+
+// NewCsvSource opens a CSV file, or stdin when the filename is -, to read in the given dialect. The returned function
+// closes the file.
+func NewCsvSource(filename string, dialect Dialect) (*Reader, func() error, error) {
+	input, err := OpenInput(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, err := NewReader(input, dialect)
+	if err != nil {
+		_ = input.Close()
 		return nil, nil, err
 	}
-	reader := csv.NewReader(file)
 
-	return reader, file.Close, nil
+	return reader, input.Close, nil
 }
 
-func NewOutputSource(filename string) (*csv.Writer, func() error, error) {
-	file, err := os.Create(filename)
+// NewOutputSource creates a CSV file, or writes to stdout when the filename is -, in the given dialect. The returned
+// function closes the file, Flush the writer first.
+func NewOutputSource(filename string, dialect Dialect) (*Writer, func() error, error) {
+	output, err := CreateOutput(filename, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	writer, err := NewWriter(output, dialect)
 	if err != nil {
+		_ = output.Close()
 		return nil, nil, err
 	}
-	writer := csv.NewWriter(file)
-	return writer, file.Close, nil
+	return writer, output.Close, nil
 }
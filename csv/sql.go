@@ -0,0 +1,356 @@
+package csv
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SQLDialect is the database an SQLWriter writes statements for.
+type SQLDialect int
+
+//go:generate stringer -type=SQLDialect
+const (
+	Postgres SQLDialect = iota
+	MySQL
+	SQLite
+)
+
+// ParseSQLDialect reads an SQLDialect by name, ignoring case. Postgresql and sqlite3 are accepted too.
+func ParseSQLDialect(name string) (SQLDialect, error) {
+	switch strings.ToLower(name) {
+	case "postgresql":
+		return Postgres, nil
+	case "sqlite3":
+		return SQLite, nil
+	}
+	for d := Postgres; d <= SQLite; d++ {
+		if strings.EqualFold(name, d.String()) {
+			return d, nil
+		}
+	}
+	return Postgres, fmt.Errorf("unrecognized SQL dialect '%s', expected postgres, mysql or sqlite", name)
+}
+
+// sqlNumber matches the numbers that can be written as SQL literals as they are. A number with a leading zero, like a
+// ZIP code, is text, as writing it as a number would lose the zeros.
+var sqlNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*|(0|[1-9][0-9]*)?\.[0-9]+)([eE][-+]?[0-9]+)?$`)
+
+// sqlMemoryRows is how many rows an SQLWriter holds in memory while it works out the types of the columns, the rest
+// are spooled to a temporary file.
+const sqlMemoryRows = 1000
+
+// SQLOptions controls how an SQLWriter writes rows.
+type SQLOptions struct {
+	Table     string            // name of the table, a dot separates the schema from the table
+	Dialect   SQLDialect        // database the statements are for
+	BatchSize int               // rows in each INSERT statement, 100 if not set
+	NoHeader  bool              // the first row is data, the columns are named "column 1", "column 2" and so on
+	Types     map[int]ValueType // value types by column number starting at 1, other columns are worked out from the rows
+}
+
+// sqlColumn is the type of a column in the table.
+type sqlColumn struct {
+	valueType ValueType
+	integer   bool // a number without a fractional part
+}
+
+// SQLWriter writes rows as a CREATE TABLE statement followed by INSERT statements. The columns are named by the header
+// row. Columns without a type are numbers or bools if all of their values are, otherwise text, so every row is held
+// back until the writer is closed, in memory for the first rows and in a temporary file after that. It has the same
+// Write and Flush as the CSV Writer, and must be closed to write the statements.
+type SQLWriter struct {
+	w          *bufio.Writer
+	options    SQLOptions
+	header     header
+	candidates [][]sqlColumn // types each column can still be, from the most to the least specific
+	pending    [][]string    // rows held in memory
+	spool      *os.File      // rows held in a temporary file once there are too many to keep in memory
+	spooled    *bufio.Writer
+	encoder    *gob.Encoder
+	columns    []sqlColumn // set once the table is created
+	batch      int         // rows in the INSERT being written
+	err        error
+}
+
+func NewSQLWriter(w io.Writer, options SQLOptions) *SQLWriter {
+	if options.BatchSize <= 0 {
+		options.BatchSize = 100
+	}
+	return &SQLWriter{w: bufio.NewWriter(w), options: options, header: newHeader(options.NoHeader)}
+}
+
+// Write keeps the first row as the column names, unless there is no header, and holds every other row back until the
+// table is created.
+func (w *SQLWriter) Write(row []string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.header.take(row) {
+		return nil
+	}
+	w.infer(row)
+	if w.spool == nil && len(w.pending) < sqlMemoryRows {
+		w.pending = append(w.pending, append([]string(nil), row...))
+		return nil
+	}
+	if w.spool == nil {
+		w.spool, w.err = os.CreateTemp("", "csv-chef-sql-")
+		if w.err != nil {
+			return w.err
+		}
+		w.spooled = bufio.NewWriter(w.spool)
+		w.encoder = gob.NewEncoder(w.spooled)
+	}
+	w.err = w.encoder.Encode(row)
+	return w.err
+}
+
+// infer removes the types that a value of the row doesn't fit from the candidates of its column.
+func (w *SQLWriter) infer(row []string) {
+	for len(w.candidates) < len(row) {
+		w.candidates = append(w.candidates, w.columnCandidates(len(w.candidates)))
+	}
+	for i, value := range row {
+		fitting := w.candidates[i][:0]
+		for _, c := range w.candidates[i] {
+			if c.fits(value) {
+				fitting = append(fitting, c)
+			}
+		}
+		w.candidates[i] = fitting
+	}
+}
+
+// columnCandidates returns the types a column can be, from its type hint when it has one. Whether a number is an
+// integer always comes from the rows, and a column with values that can't be written as its type is text.
+func (w *SQLWriter) columnCandidates(i int) []sqlColumn {
+	if valueType, ok := w.options.Types[i+1]; ok {
+		return []sqlColumn{{valueType, valueType == Number}, {valueType, false}, {String, false}}
+	}
+	return []sqlColumn{{Number, true}, {Number, false}, {Bool, false}, {String, false}}
+}
+
+// create works out the types of the columns, writes the CREATE TABLE and then the rows that were held back.
+func (w *SQLWriter) create() {
+	width := len(w.header.names)
+	if len(w.candidates) > width {
+		width = len(w.candidates)
+	}
+	w.columns = make([]sqlColumn, width)
+	for i := range w.columns {
+		candidates := w.columnCandidates(i)
+		if i < len(w.candidates) {
+			candidates = w.candidates[i]
+		}
+		// text fits anything, so there is always at least one candidate left
+		w.columns[i] = candidates[0]
+	}
+
+	if width == 0 {
+		return
+	}
+	var b strings.Builder
+	b.WriteString("CREATE TABLE " + w.table() + " (\n")
+	for i, c := range w.columns {
+		if i > 0 {
+			b.WriteString(",\n")
+		}
+		b.WriteString("  " + w.quoteName(w.name(i)) + " " + w.sqlType(c))
+	}
+	b.WriteString("\n);\n")
+	_, w.err = w.w.WriteString(b.String())
+
+	for _, row := range w.pending {
+		w.insert(row)
+	}
+	w.pending = nil
+	if w.spool != nil && w.err == nil {
+		w.insertSpooled()
+	}
+}
+
+// insertSpooled writes the rows from the temporary file.
+func (w *SQLWriter) insertSpooled() {
+	if w.err = w.spooled.Flush(); w.err != nil {
+		return
+	}
+	if _, w.err = w.spool.Seek(0, io.SeekStart); w.err != nil {
+		return
+	}
+	decoder := gob.NewDecoder(bufio.NewReader(w.spool))
+	for w.err == nil {
+		var row []string
+		if err := decoder.Decode(&row); err == io.EOF {
+			return
+		} else if err != nil {
+			w.err = err
+			return
+		}
+		w.insert(row)
+	}
+}
+
+// fits reports whether the value can be stored in the column. An empty value is always allowed, it is NULL for
+// numbers and bools.
+func (c sqlColumn) fits(value string) bool {
+	if value == "" || c.valueType == String {
+		return true
+	}
+	if CheckValue(c.valueType, value) != nil {
+		return false
+	}
+	if c.valueType == Number && !sqlNumber.MatchString(value) {
+		return false
+	}
+	if c.integer {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	}
+	return true
+}
+
+// insert adds the row to the INSERT being written, starting a new one when the last was ended.
+func (w *SQLWriter) insert(row []string) {
+	if w.err != nil {
+		return
+	}
+	var b strings.Builder
+	if w.batch == 0 {
+		names := make([]string, len(w.columns))
+		for i := range w.columns {
+			names[i] = w.quoteName(w.name(i))
+		}
+		b.WriteString("INSERT INTO " + w.table() + " (" + strings.Join(names, ", ") + ") VALUES\n(")
+	} else {
+		b.WriteString(",\n(")
+	}
+	for i, c := range w.columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		value := ""
+		if i < len(row) {
+			value = row[i]
+		}
+		b.WriteString(w.literal(c, value))
+	}
+	b.WriteByte(')')
+
+	w.batch++
+	if w.batch == w.options.BatchSize {
+		b.WriteString(";\n")
+		w.batch = 0
+	}
+	_, w.err = w.w.WriteString(b.String())
+}
+
+// literal writes the value as an SQL literal of the column's type.
+func (w *SQLWriter) literal(c sqlColumn, value string) string {
+	switch {
+	case c.valueType == String:
+		if w.options.Dialect == MySQL {
+			// backslashes are escapes in MySQL strings unless NO_BACKSLASH_ESCAPES is set
+			value = strings.ReplaceAll(value, `\`, `\\`)
+		}
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	case value == "":
+		return "NULL"
+	case c.valueType == Bool:
+		b, _ := strconv.ParseBool(value)
+		if w.options.Dialect == SQLite {
+			if b {
+				return "1"
+			}
+			return "0"
+		}
+		return strings.ToUpper(strconv.FormatBool(b))
+	}
+	// only numbers that are valid SQL fit a number column, so they are written as they are
+	return value
+}
+
+func (w *SQLWriter) sqlType(c sqlColumn) string {
+	switch {
+	case c.valueType == Number && c.integer && w.options.Dialect == SQLite:
+		return "INTEGER"
+	case c.valueType == Number && c.integer:
+		return "BIGINT"
+	case c.valueType == Number && w.options.Dialect == Postgres:
+		return "NUMERIC"
+	case c.valueType == Number && w.options.Dialect == MySQL:
+		return "DOUBLE"
+	case c.valueType == Number:
+		return "REAL"
+	case c.valueType == Bool && w.options.Dialect == SQLite:
+		return "INTEGER"
+	case c.valueType == Bool:
+		return "BOOLEAN"
+	}
+	return "TEXT"
+}
+
+// name returns the name of a column, an empty header is named by its position as it can't be quoted.
+func (w *SQLWriter) name(i int) string {
+	if name := w.header.name(i); name != "" {
+		return name
+	}
+	return fmt.Sprintf("column %d", i+1)
+}
+
+func (w *SQLWriter) table() string {
+	parts := strings.Split(w.options.Table, ".")
+	for i, part := range parts {
+		parts[i] = w.quoteName(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteName quotes a table or column name, so it can have any characters and be a reserved word.
+func (w *SQLWriter) quoteName(name string) string {
+	quote := `"`
+	if w.options.Dialect == MySQL {
+		quote = "`"
+	}
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
+
+// Flush writes any buffered statements. The rows are held back to work out the column types, so there is nothing to
+// write until the writer is closed.
+func (w *SQLWriter) Flush() {
+	if err := w.w.Flush(); err != nil && w.err == nil {
+		w.err = err
+	}
+}
+
+// Error returns any error from a previous Write, Flush or Close.
+func (w *SQLWriter) Error() error {
+	return w.err
+}
+
+// Close creates the table, writes the rows that were held back and flushes the output. The temporary file is removed,
+// but the underlying writer is not closed.
+func (w *SQLWriter) Close() error {
+	if w.err == nil && w.columns == nil {
+		w.header.take(nil)
+		w.create()
+	}
+	if w.err == nil && w.batch > 0 {
+		_, w.err = w.w.WriteString(";\n")
+		w.batch = 0
+	}
+	w.Flush()
+	if w.spool != nil {
+		_ = w.spool.Close()
+		if err := os.Remove(w.spool.Name()); err != nil && w.err == nil {
+			w.err = err
+		}
+		w.spool = nil
+	}
+	return w.err
+}
@@ -0,0 +1,149 @@
+package csv
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func writeSQL(t *testing.T, options SQLOptions, rows ...[]string) string {
+	t.Helper()
+	var out bytes.Buffer
+	w := NewSQLWriter(&out, options)
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("write error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close error = %v", err)
+	}
+	return out.String()
+}
+
+func TestSQLWriter_Write(t *testing.T) {
+	rows := [][]string{{"id", "name", "score", "ok"}, {"1", "Ann", "1.5", "true"}, {"2", "O'Brien", "", "false"}, {"3", `C:\temp`, ".5", ""}}
+
+	tests := []struct {
+		name    string
+		options SQLOptions
+		rows    [][]string
+		want    string
+	}{
+		{
+			name:    "postgres",
+			options: SQLOptions{Table: "voters"},
+			want: "CREATE TABLE \"voters\" (\n  \"id\" BIGINT,\n  \"name\" TEXT,\n  \"score\" NUMERIC,\n  \"ok\" BOOLEAN\n);\n" +
+				"INSERT INTO \"voters\" (\"id\", \"name\", \"score\", \"ok\") VALUES\n" +
+				"(1, 'Ann', 1.5, TRUE),\n(2, 'O''Brien', NULL, FALSE),\n(3, 'C:\\temp', .5, NULL);\n",
+		},
+		{
+			name:    "mysql in batches",
+			options: SQLOptions{Table: "app.voters", Dialect: MySQL, BatchSize: 2},
+			rows:    [][]string{{"id", "name"}, {"1", "Ann"}, {"2", "O'Brien"}, {"3", `C:\temp`}},
+			want: "CREATE TABLE `app`.`voters` (\n  `id` BIGINT,\n  `name` TEXT\n);\n" +
+				"INSERT INTO `app`.`voters` (`id`, `name`) VALUES\n(1, 'Ann'),\n(2, 'O''Brien');\n" +
+				"INSERT INTO `app`.`voters` (`id`, `name`) VALUES\n(3, 'C:\\\\temp');\n",
+		},
+		{
+			name:    "sqlite",
+			options: SQLOptions{Table: "voters", Dialect: SQLite},
+			rows:    [][]string{{"score", "ok"}, {"1.5", "true"}, {"", "false"}, {".5", ""}},
+			want: "CREATE TABLE \"voters\" (\n  \"score\" REAL,\n  \"ok\" INTEGER\n);\n" +
+				"INSERT INTO \"voters\" (\"score\", \"ok\") VALUES\n(1.5, 1),\n(NULL, 0),\n(.5, NULL);\n",
+		},
+		{
+			name:    "type hints",
+			options: SQLOptions{Table: "voters", Types: map[int]ValueType{1: String, 2: Number}},
+			rows:    [][]string{{"id", "n", "ok?"}, {"1", "1", "true"}, {"2", "2", "false"}},
+			want: "CREATE TABLE \"voters\" (\n  \"id\" TEXT,\n  \"n\" BIGINT,\n  \"ok?\" BOOLEAN\n);\n" +
+				"INSERT INTO \"voters\" (\"id\", \"n\", \"ok?\") VALUES\n('1', 1, TRUE),\n('2', 2, FALSE);\n",
+		},
+		{
+			name:    "no header",
+			options: SQLOptions{Table: "voters", NoHeader: true},
+			rows:    [][]string{{"id"}, {"1"}},
+			want:    "CREATE TABLE \"voters\" (\n  \"column 1\" TEXT\n);\nINSERT INTO \"voters\" (\"column 1\") VALUES\n('id'),\n('1');\n",
+		},
+		{
+			name:    "no rows",
+			options: SQLOptions{Table: "voters"},
+			rows:    [][]string{{"id", ""}},
+			want:    "CREATE TABLE \"voters\" (\n  \"id\" BIGINT,\n  \"column 2\" BIGINT\n);\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.rows == nil {
+				tt.rows = rows
+			}
+			if got := writeSQL(t, tt.options, tt.rows...); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLWriter_LeadingZerosAreText(t *testing.T) {
+	got := writeSQL(t, SQLOptions{Table: "t"}, []string{"zip", "n"}, []string{"01234", "0"}, []string{"00501", "0.5"})
+	want := "CREATE TABLE \"t\" (\n  \"zip\" TEXT,\n  \"n\" NUMERIC\n);\n" +
+		"INSERT INTO \"t\" (\"zip\", \"n\") VALUES\n('01234', 0),\n('00501', 0.5);\n"
+	if got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestSQLWriter_NumbersAreWrittenAsTheyAre(t *testing.T) {
+	tests := []struct {
+		name  string
+		types map[int]ValueType
+		value string
+		want  string
+	}{
+		{name: "fraction", value: ".5", want: "\"n\" NUMERIC\n);\nINSERT INTO \"t\" (\"n\") VALUES\n(.5);\n"},
+		{name: "exponent", value: "-1.5e3", want: "\"n\" NUMERIC\n);\nINSERT INTO \"t\" (\"n\") VALUES\n(-1.5e3);\n"},
+		{name: "hex", value: "0x1F", want: "\"n\" TEXT\n);\nINSERT INTO \"t\" (\"n\") VALUES\n('0x1F');\n"},
+		{name: "plus sign", value: "+5", want: "\"n\" TEXT\n);\nINSERT INTO \"t\" (\"n\") VALUES\n('+5');\n"},
+		{name: "underscores", value: "1_000", want: "\"n\" TEXT\n);\nINSERT INTO \"t\" (\"n\") VALUES\n('1_000');\n"},
+		{name: "trailing point", value: "5.", want: "\"n\" TEXT\n);\nINSERT INTO \"t\" (\"n\") VALUES\n('5.');\n"},
+		{
+			name:  "number type with a leading zero",
+			types: map[int]ValueType{1: Number},
+			value: "007",
+			want:  "\"n\" TEXT\n);\nINSERT INTO \"t\" (\"n\") VALUES\n('007');\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := writeSQL(t, SQLOptions{Table: "t", Types: tt.types}, []string{"n"}, []string{tt.value})
+			want := "CREATE TABLE \"t\" (\n  " + tt.want
+			if got != want {
+				t.Errorf("output = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSQLWriter_TypesComeFromEveryRow(t *testing.T) {
+	// more rows than are held in memory, with values after them that don't fit the types of the first rows
+	rows := [][]string{{"v", "zip", "n"}}
+	for i := 1; i <= sqlMemoryRows+10; i++ {
+		rows = append(rows, []string{strconv.Itoa(i), "12345", "1"})
+	}
+	rows = append(rows, []string{"N/A", "01234", "1.5", "x"})
+	got := writeSQL(t, SQLOptions{Table: "t", BatchSize: sqlMemoryRows}, rows...)
+
+	wantStart := "CREATE TABLE \"t\" (\n  \"v\" TEXT,\n  \"zip\" TEXT,\n  \"n\" NUMERIC,\n  \"column 4\" TEXT\n);\n" +
+		"INSERT INTO \"t\" (\"v\", \"zip\", \"n\", \"column 4\") VALUES\n('1', '12345', 1, ''),\n"
+	if !strings.HasPrefix(got, wantStart) {
+		t.Errorf("output starts with %q, want %q", got[:len(wantStart)], wantStart)
+	}
+	wantEnd := "('1010', '12345', 1, ''),\n('N/A', '01234', 1.5, 'x');\n"
+	if !strings.HasSuffix(got, wantEnd) {
+		t.Errorf("output = %q, want it to end with %q", got[len(got)-len(wantEnd):], wantEnd)
+	}
+	if inserts := strings.Count(got, "INSERT INTO"); inserts != 2 {
+		t.Errorf("INSERT statements = %d, want 2", inserts)
+	}
+}
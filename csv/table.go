@@ -0,0 +1,228 @@
+package csv
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"strings"
+)
+
+// TableOptions controls how a MarkdownWriter or HTMLWriter writes rows.
+type TableOptions struct {
+	NoHeader bool              // the first row is data, the columns are named "column 1", "column 2" and so on
+	Types    map[int]ValueType // value types by column number starting at 1, numbers are aligned to the right
+	Page     bool              // write a standalone HTML page with some styling, not just the table
+	Title    string            // title of the HTML page
+}
+
+// markdownEscaper escapes the characters that would format a Markdown table cell or break it apart.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "|", `\|`, "~", `\~`,
+	"\r\n", "<br>", "\n", "<br>", "\r", "<br>",
+)
+
+// MarkdownWriter writes rows as a Markdown table. The header row is the first line of the table, when there isn't one
+// the columns are named by their position. It has the same Write and Flush as the CSV Writer, and must be closed to
+// write a table without any rows.
+type MarkdownWriter struct {
+	w       *bufio.Writer
+	options TableOptions
+	header  header
+	started bool // the header of the table has been written
+	err     error
+}
+
+func NewMarkdownWriter(w io.Writer, options TableOptions) *MarkdownWriter {
+	return &MarkdownWriter{w: bufio.NewWriter(w), options: options, header: newHeader(options.NoHeader)}
+}
+
+// Write keeps the first row as the header, unless there is no header, and writes every other row as a line of the
+// table.
+func (w *MarkdownWriter) Write(row []string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.header.take(row) {
+		return nil
+	}
+	if !w.started {
+		w.start(len(row))
+	}
+	w.line(row)
+	return w.err
+}
+
+// start writes the header of the table and the line under it, which aligns numbers to the right.
+func (w *MarkdownWriter) start(width int) {
+	w.started = true
+	if len(w.header.names) > width {
+		width = len(w.header.names)
+	}
+	names := make([]string, width)
+	alignments := make([]string, width)
+	for i := range names {
+		names[i] = w.header.name(i)
+		alignments[i] = "---"
+		if w.options.Types[i+1] == Number {
+			alignments[i] = "---:"
+		}
+	}
+	w.line(names)
+	w.line(alignments)
+}
+
+func (w *MarkdownWriter) line(cells []string) {
+	if w.err != nil {
+		return
+	}
+	var b strings.Builder
+	b.WriteString("|")
+	for _, cell := range cells {
+		b.WriteString(" " + markdownEscaper.Replace(cell) + " |")
+	}
+	b.WriteString("\n")
+	_, w.err = w.w.WriteString(b.String())
+}
+
+// Flush writes any buffered lines.
+func (w *MarkdownWriter) Flush() {
+	if err := w.w.Flush(); err != nil && w.err == nil {
+		w.err = err
+	}
+}
+
+// Error returns any error from a previous Write, Flush or Close.
+func (w *MarkdownWriter) Error() error {
+	return w.err
+}
+
+// Close writes the header of the table if there weren't any rows, and flushes the output. It does not close the
+// underlying writer.
+func (w *MarkdownWriter) Close() error {
+	if w.err == nil && !w.started && len(w.header.names) > 0 {
+		w.start(0)
+	}
+	w.Flush()
+	return w.err
+}
+
+const htmlPageStart = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+table { border-collapse: collapse; font-size: 14px; }
+th, td { border: 1px solid #d0d7de; padding: 6px 12px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+tbody tr:nth-child(even) { background: #f6f8fa; }
+td.number { text-align: right; font-variant-numeric: tabular-nums; }
+</style>
+</head>
+<body>
+`
+
+const htmlPageEnd = `</body>
+</html>
+`
+
+// HTMLWriter writes rows as an HTML table, optionally on a page of its own. The header row is the head of the table,
+// when there isn't one the table doesn't have a head. It has the same Write and Flush as the CSV Writer, and must be
+// closed to end the table.
+type HTMLWriter struct {
+	w       *bufio.Writer
+	options TableOptions
+	header  header
+	started bool // the table has been started
+	err     error
+}
+
+func NewHTMLWriter(w io.Writer, options TableOptions) *HTMLWriter {
+	return &HTMLWriter{w: bufio.NewWriter(w), options: options, header: newHeader(options.NoHeader)}
+}
+
+// Write keeps the first row as the head of the table, unless there is no header, and writes every other row as a row
+// of the table.
+func (w *HTMLWriter) Write(row []string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.header.take(row) {
+		return nil
+	}
+	if !w.started {
+		w.start()
+	}
+
+	var b strings.Builder
+	b.WriteString("<tr>")
+	for i, cell := range row {
+		if w.options.Types[i+1] == Number {
+			b.WriteString(`<td class="number">`)
+		} else {
+			b.WriteString("<td>")
+		}
+		b.WriteString(escapeHTML(cell) + "</td>")
+	}
+	b.WriteString("</tr>\n")
+	w.writeString(b.String())
+	return w.err
+}
+
+// start writes the start of the page, if there is one, and the start of the table with its head.
+func (w *HTMLWriter) start() {
+	w.started = true
+	if w.options.Page {
+		w.writeString(strings.Replace(htmlPageStart, "{{title}}", html.EscapeString(w.options.Title), 1))
+	}
+	w.writeString("<table>\n")
+	if len(w.header.names) > 0 {
+		var b strings.Builder
+		b.WriteString("<thead>\n<tr>")
+		for _, name := range w.header.names {
+			b.WriteString("<th>" + escapeHTML(name) + "</th>")
+		}
+		b.WriteString("</tr>\n</thead>\n")
+		w.writeString(b.String())
+	}
+	w.writeString("<tbody>\n")
+}
+
+func (w *HTMLWriter) writeString(s string) {
+	if w.err == nil {
+		_, w.err = w.w.WriteString(s)
+	}
+}
+
+// escapeHTML escapes the value for HTML, keeping its line breaks.
+func escapeHTML(value string) string {
+	value = html.EscapeString(value)
+	return strings.NewReplacer("\r\n", "<br>", "\n", "<br>", "\r", "<br>").Replace(value)
+}
+
+// Flush writes any buffered rows.
+func (w *HTMLWriter) Flush() {
+	if err := w.w.Flush(); err != nil && w.err == nil {
+		w.err = err
+	}
+}
+
+// Error returns any error from a previous Write, Flush or Close.
+func (w *HTMLWriter) Error() error {
+	return w.err
+}
+
+// Close ends the table, and the page if there is one, and flushes the output. It does not close the underlying
+// writer.
+func (w *HTMLWriter) Close() error {
+	if !w.started {
+		w.start()
+	}
+	w.writeString("</tbody>\n</table>\n")
+	if w.options.Page {
+		w.writeString(htmlPageEnd)
+	}
+	w.Flush()
+	return w.err
+}
@@ -0,0 +1,99 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// tableWriter is a MarkdownWriter or HTMLWriter.
+type tableWriter interface {
+	Write(row []string) error
+	Close() error
+}
+
+func TestTableWriters(t *testing.T) {
+	rows := [][]string{{"id", "name"}, {"1", "A|b *c*"}, {"2", "<x> & \n y"}}
+
+	tests := []struct {
+		name    string
+		html    bool
+		options TableOptions
+		rows    [][]string
+		want    string
+	}{
+		{
+			name:    "markdown",
+			options: TableOptions{Types: map[int]ValueType{1: Number}},
+			want:    "| id | name |\n| ---: | --- |\n| 1 | A\\|b \\*c\\* |\n| 2 | \\<x\\> & <br> y |\n",
+		},
+		{
+			name:    "markdown without a header",
+			options: TableOptions{NoHeader: true},
+			rows:    [][]string{{"id"}, {"1"}, {"2"}},
+			want:    "| column 1 |\n| --- |\n| id |\n| 1 |\n| 2 |\n",
+		},
+		{
+			name: "markdown without rows",
+			rows: [][]string{{"id"}},
+			want: "| id |\n| --- |\n",
+		},
+		{
+			name:    "html",
+			html:    true,
+			options: TableOptions{Types: map[int]ValueType{1: Number}},
+			want: "<table>\n<thead>\n<tr><th>id</th><th>name</th></tr>\n</thead>\n<tbody>\n" +
+				"<tr><td class=\"number\">1</td><td>A|b *c*</td></tr>\n" +
+				"<tr><td class=\"number\">2</td><td>&lt;x&gt; &amp; <br> y</td></tr>\n" +
+				"</tbody>\n</table>\n",
+		},
+		{
+			name:    "html without a header",
+			html:    true,
+			options: TableOptions{NoHeader: true},
+			rows:    [][]string{{"a"}},
+			want:    "<table>\n<tbody>\n<tr><td>a</td></tr>\n</tbody>\n</table>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.rows == nil {
+				tt.rows = rows
+			}
+			var out bytes.Buffer
+			var w tableWriter = NewMarkdownWriter(&out, tt.options)
+			if tt.html {
+				w = NewHTMLWriter(&out, tt.options)
+			}
+			for _, row := range tt.rows {
+				if err := w.Write(row); err != nil {
+					t.Fatalf("write error = %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("close error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLWriter_Page(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewHTMLWriter(&out, TableOptions{Page: true, Title: "Q&A"})
+	_ = writer.Write([]string{"id"})
+	_ = writer.Write([]string{"1"})
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close error = %v", err)
+	}
+
+	got := out.String()
+	if !strings.HasPrefix(got, "<!DOCTYPE html>\n") || !strings.Contains(got, "<title>Q&amp;A</title>") {
+		t.Errorf("page doesn't start with a head and title: %q", got)
+	}
+	if !strings.HasSuffix(got, "<tr><td>1</td></tr>\n</tbody>\n</table>\n</body>\n</html>\n") {
+		t.Errorf("page doesn't end with the table: %q", got)
+	}
+}
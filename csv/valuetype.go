@@ -0,0 +1,48 @@
+package csv
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ValueType is the kind of value an output column holds, which decides how it is written as JSON.
+type ValueType int
+
+//go:generate stringer -type=ValueType
+const (
+	String ValueType = iota
+	Number
+	Bool
+)
+
+// ParseValueType reads a ValueType by name, ignoring case.
+func ParseValueType(name string) (ValueType, error) {
+	for v := String; v <= Bool; v++ {
+		if strings.EqualFold(name, v.String()) {
+			return v, nil
+		}
+	}
+	return String, fmt.Errorf("unrecognized type '%s', expected string, number or bool", name)
+}
+
+// CheckValue makes sure the value can be written as the type. An empty value is always allowed, it is written as
+// null for numbers and bools.
+func CheckValue(valueType ValueType, value string) error {
+	if value == "" {
+		return nil
+	}
+	switch valueType {
+	case Number:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return fmt.Errorf("value '%s' is not a number", value)
+		}
+	case Bool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("value '%s' is not true or false", value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package csv
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Writer writes rows of a CSV file in a Dialect. It quotes fields the same way as encoding/csv, but with the
+// dialect's quote character, and can quote every field.
+type Writer struct {
+	w       *bufio.Writer
+	dialect Dialect
+	special string // characters that make a field need quotes
+}
+
+// NewWriter returns a Writer for the dialect, or an error if the dialect is not valid.
+func NewWriter(w io.Writer, dialect Dialect) (*Writer, error) {
+	if err := dialect.Validate(); err != nil {
+		return nil, err
+	}
+	return &Writer{
+		w:       bufio.NewWriter(w),
+		dialect: dialect,
+		special: string([]rune{dialect.delimiter(), dialect.quote(), '\r', '\n'}),
+	}, nil
+}
+
+// Write writes a single row. Writes are buffered, so Flush must be called to make sure the row has been written.
+func (w *Writer) Write(row []string) error {
+	quote := string(w.dialect.quote())
+	for n, field := range row {
+		if n > 0 {
+			if _, err := w.w.WriteRune(w.dialect.delimiter()); err != nil {
+				return err
+			}
+		}
+
+		if !w.fieldNeedsQuotes(field) {
+			if _, err := w.w.WriteString(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := w.w.WriteString(quote); err != nil {
+			return err
+		}
+		for len(field) > 0 {
+			i := strings.IndexAny(field, quote+"\r\n")
+			if i < 0 {
+				i = len(field)
+			}
+			if _, err := w.w.WriteString(field[:i]); err != nil {
+				return err
+			}
+			field = field[i:]
+			if len(field) == 0 {
+				break
+			}
+
+			var err error
+			switch {
+			case strings.HasPrefix(field, quote):
+				_, err = w.w.WriteString(quote + quote)
+				field = field[len(quote):]
+			case field[0] == '\r':
+				if !w.dialect.CRLF {
+					err = w.w.WriteByte('\r')
+				}
+				field = field[1:]
+			case field[0] == '\n':
+				if w.dialect.CRLF {
+					_, err = w.w.WriteString("\r\n")
+				} else {
+					err = w.w.WriteByte('\n')
+				}
+				field = field[1:]
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := w.w.WriteString(quote); err != nil {
+			return err
+		}
+	}
+
+	if w.dialect.CRLF {
+		_, err := w.w.WriteString("\r\n")
+		return err
+	}
+	return w.w.WriteByte('\n')
+}
+
+// fieldNeedsQuotes reports whether the field must be quoted. Empty fields are only quoted when every field is, and
+// a field starting with a space is quoted so the space isn't lost to a reader that trims it.
+func (w *Writer) fieldNeedsQuotes(field string) bool {
+	if w.dialect.AlwaysQuote {
+		return true
+	}
+	if field == "" {
+		return false
+	}
+	if field == `\.` || strings.ContainsAny(field, w.special) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(r)
+}
+
+// Flush writes any buffered rows. Use Error to find out whether it worked.
+func (w *Writer) Flush() {
+	_ = w.w.Flush()
+}
+
+// Error returns any error from a previous Write or Flush.
+func (w *Writer) Error() error {
+	_, err := w.w.Write(nil)
+	return err
+}
@@ -0,0 +1,262 @@
+package recipe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Aggregator combines the values of a column for every row in a group into a single value.
+type Aggregator interface {
+	Add(value string)
+	Result() string
+}
+
+// AggregatorFactory returns a new, empty Aggregator for each group.
+type AggregatorFactory func() Aggregator
+
+// aggregateFunction returns the aggregate that ends a recipe, if there is one.
+func (t *Transformation) aggregateFunction(r Recipe) (*Function, bool) {
+	if len(r.Pipe) == 0 {
+		return nil, false
+	}
+	f, ok := t.registry().Lookup(r.Pipe[len(r.Pipe)-1].Name)
+	if !ok || f.Aggregate == nil {
+		return nil, false
+	}
+	return f, true
+}
+
+// IsGrouped reports whether any column is an aggregate, which means the output has one row per group instead of
+// one row per line of input. The columns that are not aggregates are the keys for the groups.
+func (t *Transformation) IsGrouped() bool {
+	for _, c := range t.Columns {
+		if _, ok := t.aggregateFunction(c); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAggregates makes sure aggregates are only used as the last step of a column recipe.
+func (t *Transformation) validateAggregates(r Recipe) error {
+	for i, o := range r.Pipe {
+		if err := t.validateAggregate(r, o.Name, i == len(r.Pipe)-1); err != nil {
+			return err
+		}
+		// an aggregate in a group or an argument is never the last step
+		var err error
+		for _, a := range o.Arguments {
+			if a.Type == Expression && err == nil {
+				forEachOperation(a.Pipe, func(n Operation) {
+					if err == nil {
+						err = t.validateAggregate(r, n.Name, false)
+					}
+				})
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAggregate checks the operation, if it is an aggregate, is the last step of a column recipe.
+func (t *Transformation) validateAggregate(r Recipe, name string, last bool) error {
+	f, ok := t.registry().Lookup(name)
+	if !ok || f.Aggregate == nil {
+		return nil
+	}
+	if r.Output.Type != Column {
+		return fmt.Errorf("aggregate %s can only be used in a column recipe", f.Name)
+	}
+	if !last {
+		return fmt.Errorf("aggregate %s must be the last step of column %s", f.Name, r.Output.Value)
+	}
+	return nil
+}
+
+// groups collects output rows into groups in place of writing them. Groups are written in the order they were
+// first seen.
+type groups struct {
+	keys       []int                     // output columns that make up the group key, 0-based
+	aggregates map[int]AggregatorFactory // output columns that are aggregated, 0-based
+	index      map[string]int
+	rows       [][]string
+	values     [][]Aggregator
+}
+
+func (t *Transformation) newGroups() *groups {
+	g := &groups{aggregates: make(map[int]AggregatorFactory), index: make(map[string]int)}
+	for c := 1; c <= len(t.Columns); c++ {
+		if f, ok := t.aggregateFunction(t.Columns[c]); ok {
+			g.aggregates[c-1] = f.Aggregate
+		} else {
+			g.keys = append(g.keys, c-1)
+		}
+	}
+	return g
+}
+
+// Write adds a transformed row to its group.
+func (g *groups) Write(row []string) error {
+	key := make([]string, len(g.keys))
+	for i, c := range g.keys {
+		key[i] = row[c]
+	}
+	k := strings.Join(key, "\x00")
+
+	i, ok := g.index[k]
+	if !ok {
+		i = len(g.rows)
+		g.index[k] = i
+		g.rows = append(g.rows, append([]string(nil), row...))
+		aggregators := make([]Aggregator, len(row))
+		for c, factory := range g.aggregates {
+			aggregators[c] = factory()
+		}
+		g.values = append(g.values, aggregators)
+	}
+	for c := range g.aggregates {
+		g.values[i][c].Add(row[c])
+	}
+	return nil
+}
+
+// Flush does nothing, the groups are only written once all the input has been read.
+func (g *groups) Flush() {}
+
+// writeTo writes one row for each group and returns how many were written.
+func (g *groups) writeTo(writer RowWriter) (int, error) {
+	for i, row := range g.rows {
+		for c := range g.aggregates {
+			row[c] = g.values[i][c].Result()
+		}
+		if err := writer.Write(row); err != nil {
+			return i, err
+		}
+	}
+	return len(g.rows), nil
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// numericArg passes a value through to be aggregated, making sure it is a number so a bad value is reported for the
+// line it came from. Empty values are allowed and are left out of the aggregate.
+func numericArg(name string) FunctionImpl {
+	return func(_ LineContext, args []string) (string, error) {
+		if args[0] == "" {
+			return "", nil
+		}
+		if _, err := strconv.ParseFloat(args[0], 64); err != nil {
+			return "", fmt.Errorf("value to %s was not numeric: %s", name, args[0])
+		}
+		return args[0], nil
+	}
+}
+
+// passArg passes a value through to be aggregated.
+func passArg(_ LineContext, args []string) (string, error) {
+	return args[0], nil
+}
+
+type countAggregator struct {
+	count int
+}
+
+func (a *countAggregator) Add(string) { a.count++ }
+
+func (a *countAggregator) Result() string { return strconv.Itoa(a.count) }
+
+type countDistinctAggregator struct {
+	seen map[string]bool
+}
+
+func (a *countDistinctAggregator) Add(value string) {
+	if value == "" {
+		return
+	}
+	if a.seen == nil {
+		a.seen = make(map[string]bool)
+	}
+	a.seen[value] = true
+}
+
+func (a *countDistinctAggregator) Result() string { return strconv.Itoa(len(a.seen)) }
+
+type sumAggregator struct {
+	sum   float64
+	count int
+}
+
+func (a *sumAggregator) Add(value string) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+	a.sum += n
+	a.count++
+}
+
+func (a *sumAggregator) Result() string { return formatNumber(a.sum) }
+
+type avgAggregator struct {
+	sumAggregator
+}
+
+func (a *avgAggregator) Result() string {
+	if a.count == 0 {
+		return ""
+	}
+	return formatNumber(a.sum / float64(a.count))
+}
+
+// extremeAggregator keeps the smallest or largest value. Values are compared as numbers when both are numeric and
+// as strings otherwise.
+type extremeAggregator struct {
+	max   bool
+	value string
+	set   bool
+}
+
+func (a *extremeAggregator) Add(value string) {
+	if value == "" {
+		return
+	}
+	if !a.set {
+		a.value = value
+		a.set = true
+		return
+	}
+	c := compareValues(value, a.value)
+	if (a.max && c > 0) || (!a.max && c < 0) {
+		a.value = value
+	}
+}
+
+func (a *extremeAggregator) Result() string { return a.value }
+
+type firstAggregator struct {
+	value string
+	set   bool
+}
+
+func (a *firstAggregator) Add(value string) {
+	if !a.set {
+		a.value = value
+		a.set = true
+	}
+}
+
+func (a *firstAggregator) Result() string { return a.value }
+
+type lastAggregator struct {
+	value string
+}
+
+func (a *lastAggregator) Add(value string) { a.value = value }
+
+func (a *lastAggregator) Result() string { return a.value }
@@ -0,0 +1,124 @@
+package recipe
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransformation_ExecuteGrouped(t *testing.T) {
+	input := "state,city,amount\nCO,Denver,10\nUT,Provo,5\nCO,Boulder,2.5\nCO,Denver,\nUT,Ogden,7\n"
+
+	tests := []struct {
+		name             string
+		recipe           string
+		input            string
+		processHeader    bool
+		onError          ErrorMode
+		want             string
+		wantResult       TransformationResult
+		wantParseErrText string
+		wantErrText      string
+	}{
+		{
+			name:          "one row per group in the order first seen",
+			recipe:        "1 <- 1\n2 <- count\n3 <- 3 -> sum\n4 <- avg(3)\n5 <- 2 -> countDistinct\n",
+			input:         input,
+			processHeader: true,
+			want:          "state,city,amount,column 4,column 5\nCO,3,12.5,6.25,2\nUT,2,12,6,2\n",
+			wantResult:    TransformationResult{HeaderLines: 1, Lines: 5, Kept: 5, Groups: 2},
+		},
+		{
+			name:          "first, last, min and max",
+			recipe:        "1 <- 1\n2 <- 2 -> first\n3 <- 2 -> last\n4 <- 3 -> min\n5 <- 3 -> max\n6 <- 2 -> max\n",
+			input:         input,
+			processHeader: true,
+			want:          "state,city,amount,column 4,column 5,column 6\nCO,Denver,Denver,2.5,10,Denver\nUT,Provo,Ogden,5,7,Provo\n",
+			wantResult:    TransformationResult{HeaderLines: 1, Lines: 5, Kept: 5, Groups: 2},
+		},
+		{
+			name:       "group key built from a pipeline and more than one column",
+			recipe:     "1 <- 1 -> lowercase\n2 <- 2 -> firstChars(\"1\")\n3 <- count\n",
+			input:      "CO,Denver\nco,Dillon\nCO,Boulder\n",
+			want:       "co,D,2\nco,B,1\n",
+			wantResult: TransformationResult{Lines: 3, Kept: 3, Groups: 2},
+		},
+		{
+			name:          "filtered lines are not aggregated",
+			recipe:        "1 <- sum(3)\n? <- 1 -> eq(\"UT\")\n",
+			input:         input,
+			processHeader: true,
+			want:          "state\n12\n",
+			wantResult:    TransformationResult{HeaderLines: 1, Lines: 5, Kept: 2, Skipped: 3, Groups: 1},
+		},
+		{
+			name:       "no input is no groups",
+			recipe:     "1 <- 1\n2 <- count\n",
+			input:      "",
+			want:       "",
+			wantResult: TransformationResult{},
+		},
+		{
+			name:        "values that are not numbers are errors for their line",
+			recipe:      "1 <- 1\n2 <- sum(2)\n",
+			input:       "a,1\na,x\n",
+			wantErrText: "line 2 / column 2: sum(): value to sum was not numeric: x",
+		},
+		{
+			name:       "lines with errors can be skipped",
+			recipe:     "1 <- 1\n2 <- sum(2)\n",
+			input:      "a,1\na,x\na,2\n",
+			onError:    Skip,
+			want:       "a,3\n",
+			wantResult: TransformationResult{Lines: 3, Kept: 2, Rejected: 1, Groups: 1},
+		},
+		{
+			name:             "aggregate must be last",
+			recipe:           "1 <- 1\n2 <- 2 -> sum -> numberFormat(\"2\")\n",
+			wantParseErrText: "error - line 2: aggregate sum must be the last step of column 2",
+		},
+		{
+			name:             "aggregate can only be used for columns",
+			recipe:           "$total <- sum(2)\n1 <- $total\n",
+			wantParseErrText: "error - line 1: aggregate sum can only be used in a column recipe",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformation, err := Parse(strings.NewReader(tt.recipe))
+			if tt.wantParseErrText != "" {
+				if err == nil || err.Error() != tt.wantParseErrText {
+					t.Errorf("parse error = %v, want %v", err, tt.wantParseErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+
+			for _, workers := range []int{1, 4} {
+				var b bytes.Buffer
+				options := ExecuteOptions{Workers: workers, OnError: tt.onError}
+				result, err := transformation.ExecuteWithOptions(csv.NewReader(strings.NewReader(tt.input)), csv.NewWriter(&b), tt.processHeader, -1, options)
+				if tt.wantErrText != "" {
+					if err == nil || err.Error() != tt.wantErrText {
+						t.Errorf("workers %d: error = %v, want %v", workers, err, tt.wantErrText)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("workers %d: execute error = %v", workers, err)
+					continue
+				}
+				if !reflect.DeepEqual(*result, tt.wantResult) {
+					t.Errorf("workers %d: result = %+v, want %+v", workers, *result, tt.wantResult)
+				}
+				if got := b.String(); got != tt.want {
+					t.Errorf("workers %d: output = %v, want %v", workers, got, tt.want)
+				}
+			}
+		})
+	}
+}
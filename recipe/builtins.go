@@ -0,0 +1,412 @@
+package recipe
+
+import (
+	"os"
+	"strconv"
+)
+
+var builtinFunctions = []Function{
+	{
+		Name:        "uppercase",
+		Args:        []string{"?"},
+		Description: "transforms characters in the value to uppercase",
+		Impl:        infallible(Uppercase),
+	},
+	{
+		Name:        "lowercase",
+		Args:        []string{"?"},
+		Description: "transforms characters in the value to lowercase",
+		Impl:        infallible(Lowercase),
+	},
+	{
+		Name:        "join",
+		Args:        []string{"?"},
+		Description: "joins the value on the left with the rest of the recipe on the right, inserted for `+`",
+		Impl:        unary(func(s string) (string, error) { return s, nil }),
+	},
+	{
+		Name:        "add",
+		Args:        []string{"?", "?"},
+		Description: "returns the sum of two numeric values",
+		Impl:        binary(Add),
+	},
+	{
+		Name:        "subtract",
+		Args:        []string{"?", "?"},
+		Description: "returns the first numeric value minus the second",
+		Impl:        binary(Subtract),
+	},
+	{
+		Name:        "multiply",
+		Args:        []string{"?", "?"},
+		Description: "returns the product of two numeric values",
+		Impl:        binary(Multiply),
+	},
+	{
+		Name:        "divide",
+		Args:        []string{"?", "?"},
+		Description: "returns the first numeric value divided by the second",
+		Impl:        binary(Divide),
+	},
+	{
+		Name:        "change",
+		Args:        []string{"from", "to", "input"},
+		Description: "returns `to` if input is `from`, otherwise the input unchanged",
+		Impl:        ternary(Change),
+	},
+	{
+		Name:        "changei",
+		Args:        []string{"from", "to", "input"},
+		Description: "case-insensitive version of change",
+		Impl:        ternary(ChangeI),
+	},
+	{
+		Name:        "ifempty",
+		Aliases:     []string{"isempty"},
+		Args:        []string{"emptyVal", "notEmptyVal", "input"},
+		Description: "returns `emptyVal` if input is empty, otherwise `notEmptyVal`",
+		Impl:        ternary(IfEmpty),
+	},
+	{
+		Name:        "numberformat",
+		Args:        []string{"digits", "?"},
+		Description: "formats a number with `digits` digits after the decimal",
+		Impl:        binary(NumberFormat),
+	},
+	{
+		Name:        "lineno",
+		Args:        []string{},
+		Description: "returns the current line number",
+		Impl: func(ctx LineContext, _ []string) (string, error) {
+			return strconv.Itoa(ctx.LineNo), nil
+		},
+	},
+	{
+		Name:        "filename",
+		Args:        []string{},
+		Description: "returns the path of the input file the line was read from",
+		Impl: func(ctx LineContext, _ []string) (string, error) {
+			return ctx.Filename, nil
+		},
+	},
+	{
+		Name:        "env",
+		Args:        []string{"name"},
+		Description: "returns the value of the environment variable, empty if it isn't set",
+		Impl: func(_ LineContext, args []string) (string, error) {
+			return os.Getenv(args[0]), nil
+		},
+	},
+	{
+		Name:        "subindex",
+		Args:        []string{},
+		Description: "returns the position of the row among the rows exploded from the same line, starting at 1",
+		Impl: func(ctx LineContext, _ []string) (string, error) {
+			return strconv.Itoa(ctx.SubIndex), nil
+		},
+	},
+	{
+		Name:        "explode",
+		Args:        []string{"separator", "?"},
+		Description: "splits the value on `separator` and writes one output row for each part, repeating the other columns",
+		Impl: func(ctx LineContext, args []string) (string, error) {
+			return Explode(ctx, args[0], args[1])
+		},
+	},
+	{
+		Name:        "removedigits",
+		Args:        []string{"?"},
+		Description: "strips all digit characters from the value",
+		Impl:        unary(RemoveDigits),
+	},
+	{
+		Name:        "onlydigits",
+		Args:        []string{"?"},
+		Description: "strips all characters except digits from the value",
+		Impl:        unary(OnlyDigits),
+	},
+	{
+		Name:        "mod",
+		Args:        []string{"x", "y"},
+		Description: "returns the remainder of dividing integer x by integer y",
+		Impl:        binary(Modulus),
+	},
+	{
+		Name:        "trim",
+		Args:        []string{"?"},
+		Description: "removes leading and trailing whitespace",
+		Impl:        unary(Trim),
+	},
+	{
+		Name:        "firstchars",
+		Args:        []string{"count", "input"},
+		Description: "returns the first `count` characters of the input",
+		Impl:        binary(FirstChars),
+	},
+	{
+		Name:        "lastchars",
+		Args:        []string{"count", "input"},
+		Description: "returns the last `count` characters of the input",
+		Impl:        binary(LastChars),
+	},
+	{
+		Name:        "repeat",
+		Args:        []string{"count", "?"},
+		Description: "returns the input repeated `count` times",
+		Impl:        binary(Repeat),
+	},
+	{
+		Name:        "replace",
+		Args:        []string{"search", "replace", "?"},
+		Description: "replaces every `search` found in the input with `replace`",
+		Impl:        ternary(ReplaceString),
+	},
+	{
+		Name:        "today",
+		Args:        []string{},
+		Description: "returns today's date in YYYY-mm-dd format",
+		Impl: func(_ LineContext, _ []string) (string, error) {
+			return Today(Now)
+		},
+	},
+	{
+		Name:        "now",
+		Args:        []string{},
+		Description: "returns the current date and time in RFC 3339 format",
+		Impl: func(_ LineContext, _ []string) (string, error) {
+			return NowTime(Now)
+		},
+	},
+	{
+		Name:        "formatdate",
+		Args:        []string{"format", "date"},
+		Description: "formats an RFC 3339 date, passing anything else through unchanged",
+		Impl:        binary(FormatDate),
+	},
+	{
+		Name:        "formatdatef",
+		Args:        []string{"format", "date"},
+		Description: "formats an RFC 3339 date, anything else is an error",
+		Impl:        binary(FormatDateF),
+	},
+	{
+		Name:        "readdate",
+		Aliases:     []string{"normalize_date"},
+		Args:        []string{"format", "date"},
+		Description: "reads a date in the given format and returns it in RFC 3339 format, passing unrecognized input through",
+		Impl:        binary(ReadDate),
+	},
+	{
+		Name:        "readdatef",
+		Args:        []string{"format", "date"},
+		Description: "reads a date in the given format and returns it in RFC 3339 format, unrecognized input is an error",
+		Impl:        binary(ReadDateF),
+	},
+	{
+		Name:        "smartdate",
+		Args:        []string{"date"},
+		Description: "tries to read a date in any reasonable format and returns it in RFC 3339 format",
+		Impl:        unary(SmartDate),
+	},
+	{
+		Name:        "ispast",
+		Args:        []string{"past", "future", "date"},
+		Description: "returns `past` if the date is in the past, otherwise `future`",
+		Impl:        ternary(IsPast),
+	},
+	{
+		Name:        "isfuture",
+		Args:        []string{"future", "past", "date"},
+		Description: "returns `future` if the date is in the future, otherwise `past`",
+		Impl:        ternary(IsFuture),
+	},
+	{
+		Name:        "eq",
+		Args:        []string{"value", "input"},
+		Description: "returns true if the input is the same as `value`, comparing numbers as numbers, otherwise false",
+		Impl:        binary(Eq),
+	},
+	{
+		Name:        "count",
+		Args:        []string{},
+		Description: "aggregate, returns the number of lines in the group",
+		Impl:        func(_ LineContext, _ []string) (string, error) { return "", nil },
+		Aggregate:   func() Aggregator { return &countAggregator{} },
+	},
+	{
+		Name:        "countdistinct",
+		Args:        []string{"?"},
+		Description: "aggregate, returns the number of different non-empty values in the group",
+		Impl:        passArg,
+		Aggregate:   func() Aggregator { return &countDistinctAggregator{} },
+	},
+	{
+		Name:        "sum",
+		Args:        []string{"?"},
+		Description: "aggregate, returns the sum of the numeric values in the group",
+		Impl:        numericArg("sum"),
+		Aggregate:   func() Aggregator { return &sumAggregator{} },
+	},
+	{
+		Name:        "avg",
+		Args:        []string{"?"},
+		Description: "aggregate, returns the average of the numeric values in the group, ignoring empty values",
+		Impl:        numericArg("avg"),
+		Aggregate:   func() Aggregator { return &avgAggregator{} },
+	},
+	{
+		Name:        "min",
+		Args:        []string{"?"},
+		Description: "aggregate, returns the smallest non-empty value in the group",
+		Impl:        passArg,
+		Aggregate:   func() Aggregator { return &extremeAggregator{} },
+	},
+	{
+		Name:        "max",
+		Args:        []string{"?"},
+		Description: "aggregate, returns the largest non-empty value in the group",
+		Impl:        passArg,
+		Aggregate:   func() Aggregator { return &extremeAggregator{max: true} },
+	},
+	{
+		Name:        "first",
+		Args:        []string{"?"},
+		Description: "aggregate, returns the value from the first line in the group",
+		Impl:        passArg,
+		Aggregate:   func() Aggregator { return &firstAggregator{} },
+	},
+	{
+		Name:        "last",
+		Args:        []string{"?"},
+		Description: "aggregate, returns the value from the last line in the group",
+		Impl:        passArg,
+		Aggregate:   func() Aggregator { return &lastAggregator{} },
+	},
+	{
+		Name:        "lookup",
+		Args:        []string{"name", "column", "key"},
+		Description: "returns `column` from the row of the named @lookup table whose key matches",
+		Impl: func(ctx LineContext, args []string) (string, error) {
+			return LookupValue(ctx, args[0], args[1], args[2])
+		},
+		NumberArgs: []int{1},
+	},
+	{
+		Name:        "ne",
+		Args:        []string{"value", "input"},
+		Description: "returns true if the input is not the same as `value`, otherwise false",
+		Impl:        binary(Ne),
+	},
+	{
+		Name:        "gt",
+		Args:        []string{"value", "input"},
+		Description: "returns true if the input is greater than `value`, comparing numbers as numbers",
+		Impl:        binary(Gt),
+	},
+	{
+		Name:        "lt",
+		Args:        []string{"value", "input"},
+		Description: "returns true if the input is less than `value`, comparing numbers as numbers",
+		Impl:        binary(Lt),
+	},
+	{
+		Name:        "ge",
+		Args:        []string{"value", "input"},
+		Description: "returns true if the input is greater than or the same as `value`",
+		Impl:        binary(Ge),
+	},
+	{
+		Name:        "le",
+		Args:        []string{"value", "input"},
+		Description: "returns true if the input is less than or the same as `value`",
+		Impl:        binary(Le),
+	},
+	{
+		Name:        "contains",
+		Args:        []string{"search", "input"},
+		Description: "returns true if the input contains `search`",
+		Impl:        binary(Contains),
+	},
+	{
+		Name:        "startswith",
+		Args:        []string{"prefix", "input"},
+		Description: "returns true if the input starts with `prefix`",
+		Impl:        binary(StartsWith),
+	},
+	{
+		Name:        "endswith",
+		Args:        []string{"suffix", "input"},
+		Description: "returns true if the input ends with `suffix`",
+		Impl:        binary(EndsWith),
+	},
+	{
+		Name:        "matches",
+		Args:        []string{"pattern", "input"},
+		Description: "returns true if the regular expression `pattern` matches the input",
+		Impl:        binary(Matches),
+	},
+	{
+		Name:        "and",
+		Args:        []string{"value", "input"},
+		Description: "returns true if both `value` and the input are true",
+		Impl:        binary(And),
+	},
+	{
+		Name:        "or",
+		Args:        []string{"value", "input"},
+		Description: "returns true if either `value` or the input is true",
+		Impl:        binary(Or),
+	},
+	{
+		Name:        "not",
+		Args:        []string{"input"},
+		Description: "returns true if the input is false and false if it is true",
+		Impl:        unary(Not),
+	},
+	{
+		Name:        "when",
+		Args:        []string{"condition", "then", "else"},
+		Description: "returns `then` if the condition is true, otherwise `else`",
+		Impl:        ternary(When),
+	},
+	{
+		Name:        "case",
+		Aliases:     []string{"switch"},
+		Args:        []string{"match", "result", "...", "default", "input"},
+		Description: "returns the result paired with the first match that is the same as the input, or the default",
+		Impl: func(_ LineContext, args []string) (string, error) {
+			return Case(args...)
+		},
+		Variadic: true,
+	},
+	{
+		Name:        "fake",
+		Args:        []string{"kind"},
+		Description: "returns fake data of the given kind, ex: name, firstname, lastname, address, city, state, zipcode, phone, email",
+		Impl:        unary(Fake),
+	},
+}
+
+func infallible(fn func(string) string) FunctionImpl {
+	return func(_ LineContext, args []string) (string, error) {
+		return fn(args[0]), nil
+	}
+}
+
+func unary(fn func(string) (string, error)) FunctionImpl {
+	return func(_ LineContext, args []string) (string, error) {
+		return fn(args[0])
+	}
+}
+
+func binary(fn func(string, string) (string, error)) FunctionImpl {
+	return func(_ LineContext, args []string) (string, error) {
+		return fn(args[0], args[1])
+	}
+}
+
+func ternary(fn func(string, string, string) (string, error)) FunctionImpl {
+	return func(_ LineContext, args []string) (string, error) {
+		return fn(args[0], args[1], args[2])
+	}
+}
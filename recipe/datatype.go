@@ -10,4 +10,7 @@ const (
 	Literal
 	Placeholder
 	Header
+	Filter
+	NamedColumn
+	Expression
 )
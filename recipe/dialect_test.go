@@ -0,0 +1,93 @@
+package recipe
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dstockto/csv-chef/csv"
+)
+
+func TestTransformation_ExecuteDialect(t *testing.T) {
+	tests := []struct {
+		name             string
+		recipe           string
+		input            string
+		want             string
+		wantParseErrText string
+	}{
+		{
+			name:   "semicolons in, pipes out",
+			recipe: "@input delimiter \";\"\n@output delimiter \"|\"\n1 <- 2\n2 <- 1\n",
+			input:  "a;b\n\"c;d\";e\n",
+			want:   "b|a\ne|c;d\n",
+		},
+		{
+			name:             "unknown option",
+			recipe:           "@input separator \";\"\n1 <- 1\n",
+			wantParseErrText: "error - line 1: unrecognized dialect option separator, expected one of delimiter, quote, comment, lazyquotes, alwaysquote, crlf",
+		},
+		{
+			name:             "options are needed",
+			recipe:           "@output\n1 <- 1\n",
+			wantParseErrText: "error - line 1: expected dialect option but found EOF",
+		},
+		{
+			name:             "single characters only",
+			recipe:           "@input delimiter \";;\"\n1 <- 1\n",
+			wantParseErrText: "error - line 1: dialect option delimiter must be a single character, found ;;",
+		},
+		{
+			name:             "delimiter and quote differ",
+			recipe:           "@output delimiter \"\\\"\"\n1 <- 1\n",
+			wantParseErrText: "error - line 1: delimiter and quote must be different",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformation, err := Parse(strings.NewReader(tt.recipe))
+			if tt.wantParseErrText != "" {
+				if err == nil || err.Error() != tt.wantParseErrText {
+					t.Errorf("parse error = %v, want %v", err, tt.wantParseErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+
+			reader, err := csv.NewReader(strings.NewReader(tt.input), transformation.InputDialect)
+			if err != nil {
+				t.Fatalf("reader error = %v", err)
+			}
+			var b bytes.Buffer
+			writer, err := csv.NewWriter(&b, transformation.OutputDialect)
+			if err != nil {
+				t.Fatalf("writer error = %v", err)
+			}
+			if _, err := transformation.Execute(reader, writer, false, -1); err != nil {
+				t.Fatalf("execute error = %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_DialectString(t *testing.T) {
+	// what Dialect.String writes can be read back by a recipe
+	dialects := []csv.Dialect{
+		{Delimiter: '\t', Quote: '\''},
+		{Comment: '#', LazyQuotes: true, AlwaysQuote: true, CRLF: true},
+	}
+	for _, dialect := range dialects {
+		transformation, err := Parse(strings.NewReader("@input " + dialect.String() + "\n1 <- 1\n"))
+		if err != nil {
+			t.Fatalf("parse error = %v", err)
+		}
+		if transformation.InputDialect != dialect {
+			t.Errorf("parsed dialect = %v, want %v", transformation.InputDialect, dialect)
+		}
+	}
+}
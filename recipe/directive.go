@@ -0,0 +1,232 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dstockto/csv-chef/csv"
+)
+
+// directiveParsers handle the recipe lines that start with @, which configure the recipe instead of assigning a
+// value. They are keyed by the directive name without the @.
+var directiveParsers = map[string]func(p *Parser, t *Transformation) error{
+	"lookup": parseLookupDirective,
+	"input":  parseDialectDirective(func(t *Transformation) *csv.Dialect { return &t.InputDialect }),
+	"output": parseDialectDirective(func(t *Transformation) *csv.Dialect { return &t.OutputDialect }),
+	"type":   parseTypeDirective,
+	"fields": parseFieldsDirective,
+	"param":  parseParamDirective,
+}
+
+// parseDirective parses the rest of a line that started with the given directive.
+func parseDirective(p *Parser, t *Transformation, directive string) error {
+	parse, ok := directiveParsers[strings.ToLower(strings.TrimPrefix(directive, "@"))]
+	if !ok {
+		return fmt.Errorf("unrecognized directive %s", directive)
+	}
+	if err := parse(p, t); err != nil {
+		return err
+	}
+
+	// only a comment can follow a directive
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok != EOF && tok != COMMENT {
+		return fmt.Errorf("unexpected %s after %s", lit, directive)
+	}
+	return nil
+}
+
+// expect scans the next token, returning an error describing what was wanted if it is not the expected type.
+func expect(p *Parser, want Token, description string) (string, error) {
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok != want {
+		return "", fmt.Errorf("expected %s but found %s", description, lit)
+	}
+	return lit, nil
+}
+
+// expectKeyword scans the next token, which must be the keyword, ignoring case.
+func expectKeyword(p *Parser, keyword string) error {
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok != FUNCTION || !strings.EqualFold(lit, keyword) {
+		return fmt.Errorf("expected %s but found %s", keyword, lit)
+	}
+	return nil
+}
+
+// parseLookupDirective reads a lookup declaration, ex: @lookup parties = "parties.csv" key 1 default "unknown"
+// The default is optional, without one a missing key is an error. The first row of the file is a header, unless
+// noheader is given after the key.
+func parseLookupDirective(p *Parser, t *Transformation) error {
+	name, err := expect(p, FUNCTION, "lookup name")
+	if err != nil {
+		return err
+	}
+	if _, ok := t.Lookups[name]; ok {
+		return fmt.Errorf("lookup %s already defined", name)
+	}
+	if _, err := expect(p, EQUALS, "="); err != nil {
+		return err
+	}
+	path, err := expect(p, LITERAL, "quoted lookup file path")
+	if err != nil {
+		return err
+	}
+	if err := expectKeyword(p, "key"); err != nil {
+		return err
+	}
+	keyColumn, err := expect(p, COLUMN_ID, "key column number")
+	if err != nil {
+		return err
+	}
+	key, _ := strconv.Atoi(keyColumn)
+	if key < 1 {
+		return fmt.Errorf("lookup key column must be 1 or more, found %d", key)
+	}
+
+	lookup := &Lookup{Name: name, Path: path, KeyColumn: key}
+
+	for {
+		tok, lit := p.scanIgnoreWhitespace()
+		if tok == FUNCTION && strings.EqualFold(lit, "default") && !lookup.HasDefault {
+			lookup.Default, err = expect(p, LITERAL, "quoted default value")
+			if err != nil {
+				return err
+			}
+			lookup.HasDefault = true
+		} else if tok == FUNCTION && strings.EqualFold(lit, "noheader") && !lookup.NoHeader {
+			lookup.NoHeader = true
+		} else {
+			p.unscan()
+			break
+		}
+	}
+
+	if t.Lookups == nil {
+		t.Lookups = make(map[string]*Lookup)
+	}
+	t.Lookups[name] = lookup
+	return nil
+}
+
+// parseParamDirective reads a parameter declaration, ex: @param batch default "2026Q1"
+// A parameter without a default is required, the recipe can't be parsed unless it is set.
+func parseParamDirective(p *Parser, t *Transformation) error {
+	name, err := expect(p, FUNCTION, "parameter name")
+	if err != nil {
+		return err
+	}
+	if t.declared[name] {
+		return fmt.Errorf("parameter %s already declared", name)
+	}
+	if t.declared == nil {
+		t.declared = make(map[string]bool)
+	}
+	t.declared[name] = true
+
+	tok, lit := p.scanIgnoreWhitespace()
+	if tok == FUNCTION && strings.EqualFold(lit, "default") {
+		value, err := expect(p, LITERAL, "quoted default value")
+		if err != nil {
+			return err
+		}
+		if _, ok := t.Params[name]; !ok {
+			t.setParam(name, value)
+		}
+		return nil
+	}
+	p.unscan()
+	if _, ok := t.Params[name]; !ok {
+		return fmt.Errorf("parameter %s is required but was not set", name)
+	}
+	return nil
+}
+
+// parseDialectDirective returns a parser for the options of the input or output dialect, ex:
+// @input delimiter ";" quote "'" lazyquotes
+// Options that take a character are followed by it in quotes, the others are turned on by naming them.
+func parseDialectDirective(dialect func(t *Transformation) *csv.Dialect) func(p *Parser, t *Transformation) error {
+	return func(p *Parser, t *Transformation) error {
+		d := *dialect(t)
+		options := 0
+		for {
+			tok, lit := p.scanIgnoreWhitespace()
+			if tok == EOF || tok == COMMENT {
+				p.unscan()
+				break
+			}
+			if tok != FUNCTION {
+				return fmt.Errorf("expected dialect option but found %s", lit)
+			}
+			option := lit
+
+			var value string
+			if tok, lit = p.scanIgnoreWhitespace(); tok == LITERAL {
+				value = lit
+			} else {
+				p.unscan()
+			}
+			if err := d.Set(option, value); err != nil {
+				return err
+			}
+			options++
+		}
+		if options == 0 {
+			return errors.New("expected dialect option but found EOF")
+		}
+		if err := d.Validate(); err != nil {
+			return err
+		}
+		*dialect(t) = d
+		return nil
+	}
+}
+
+// parseTypeDirective reads the type of an output column, ex: @type 3 number
+// Types decide how values are written as JSON, and values that don't match are row errors.
+func parseTypeDirective(p *Parser, t *Transformation) error {
+	lit, err := expect(p, COLUMN_ID, "column number")
+	if err != nil {
+		return err
+	}
+	column, _ := strconv.Atoi(lit)
+	if _, ok := t.Types[column]; ok {
+		return fmt.Errorf("type of column %d already defined", column)
+	}
+	name, err := expect(p, FUNCTION, "type")
+	if err != nil {
+		return err
+	}
+	valueType, err := csv.ParseValueType(name)
+	if err != nil {
+		return err
+	}
+
+	if t.Types == nil {
+		t.Types = make(map[int]csv.ValueType)
+	}
+	t.Types[column] = valueType
+	return nil
+}
+
+// parseFieldsDirective reads the field paths that become the columns of JSON input, ex: @fields "id" "address.city"
+// Column 1 is the first field given. Fields can be spread over more than one @fields line.
+func parseFieldsDirective(p *Parser, t *Transformation) error {
+	fields := 0
+	for {
+		tok, lit := p.scanIgnoreWhitespace()
+		if tok != LITERAL {
+			p.unscan()
+			break
+		}
+		t.Fields = append(t.Fields, lit)
+		fields++
+	}
+	if fields == 0 {
+		_, err := expect(p, LITERAL, "quoted field path")
+		return err
+	}
+	return nil
+}
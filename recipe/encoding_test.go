@@ -0,0 +1,42 @@
+package recipe
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dstockto/csv-chef/csv"
+)
+
+func TestTransformation_ExecuteEncoding(t *testing.T) {
+	transformation, err := Parse(strings.NewReader("1 <- @name\n2 <- 2\n3 <- 1\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+
+	decoded, err := csv.NewDecoder(strings.NewReader("name,city\nZo\xeb,\x80\n"), "windows-1252")
+	if err != nil {
+		t.Fatalf("decoder error = %v", err)
+	}
+	reader, _ := csv.NewReader(decoded, csv.Dialect{})
+	var b bytes.Buffer
+	encoded, err := csv.NewEncoder(&b, "utf-16le")
+	if err != nil {
+		t.Fatalf("encoder error = %v", err)
+	}
+	writer, _ := csv.NewWriter(encoded, csv.Dialect{})
+
+	if _, err := transformation.Execute(reader, writer, true, -1); err != nil {
+		t.Fatalf("execute error = %v", err)
+	}
+	if err := writer.Error(); err != nil {
+		t.Fatalf("write error = %v", err)
+	}
+	if err := encoded.Close(); err != nil {
+		t.Fatalf("close error = %v", err)
+	}
+	want := "\xff\xfen\x00a\x00m\x00e\x00,\x00c\x00i\x00t\x00y\x00,\x00c\x00o\x00l\x00u\x00m\x00n\x00 \x003\x00\n\x00Z\x00o\x00\xeb\x00,\x00\xac\x20,\x00Z\x00o\x00\xeb\x00\n\x00"
+	if got := b.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package recipe
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type ErrorMode int
+
+//go:generate stringer -type=ErrorMode
+const (
+	Fail ErrorMode = iota
+	Skip
+	Reject
+)
+
+// ParseErrorMode reads an ErrorMode by name, ignoring case.
+func ParseErrorMode(mode string) (ErrorMode, error) {
+	for m := Fail; m <= Reject; m++ {
+		if strings.EqualFold(mode, m.String()) {
+			return m, nil
+		}
+	}
+	return Fail, fmt.Errorf("unrecognized error mode '%s', expected fail, skip or reject", mode)
+}
+
+// RowError is an error from transforming a single row of input.
+type RowError struct {
+	LineNo int
+	Target string // the recipe that failed, ex: column 2, or input if the row could not be read
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d / %s: %v", e.LineNo, e.Target, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// isRowReadError reports whether an error from reading input only affects the row being read, which means the
+// reader can keep going with the next row.
+func isRowReadError(err error) bool {
+	var parseError *csv.ParseError
+	return errors.As(err, &parseError)
+}
+
+// rejectRow handles an error for a single row according to the ErrorMode. When the mode is Fail, the error is
+// returned so execution stops. Rejected rows are written with the line number, failing target and error appended.
+func (t *Transformation) rejectRow(options ExecuteOptions, row []string, lineNo int, err error, result *TransformationResult) error {
+	switch options.OnError {
+	case Skip:
+		result.Rejected++
+		return nil
+	case Reject:
+		target := "input"
+		message := err.Error()
+		var rowError *RowError
+		if errors.As(err, &rowError) {
+			target = rowError.Target
+			message = rowError.Err.Error()
+		}
+		rejected := append(append([]string(nil), row...), strconv.Itoa(lineNo), target, message)
+		if err := options.Rejects.Write(rejected); err != nil {
+			return err
+		}
+		result.Rejected++
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package recipe
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseErrorMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    ErrorMode
+		wantErr bool
+	}{
+		{mode: "fail", want: Fail},
+		{mode: "skip", want: Skip},
+		{mode: "REJECT", want: Reject},
+		{mode: "ignore", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			got, err := ParseErrorMode(tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseErrorMode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseErrorMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformation_ExecuteOnError(t *testing.T) {
+	tests := []struct {
+		name          string
+		recipe        string
+		input         string
+		processHeader bool
+		onError       ErrorMode
+		want          string
+		wantRejects   string
+		wantResult    TransformationResult
+		wantErrText   string
+	}{
+		{
+			name:        "fail stops at the first error",
+			recipe:      "1 <- 1\n2 <- add(2, \"1\") -> numberFormat(\"0\")\n",
+			input:       "a,1\nb,x\nc,3\n",
+			onError:     Fail,
+			want:        "a,2\n",
+			wantErrText: "line 2 / column 2: add(): first arg to Add was not numeric: x",
+		},
+		{
+			name:       "skip leaves out rows with errors",
+			recipe:     "1 <- 1\n2 <- add(2, \"1\") -> numberFormat(\"0\")\n",
+			input:      "a,1\nb,x\nc,3\n",
+			onError:    Skip,
+			want:       "a,2\nc,4\n",
+			wantResult: TransformationResult{Lines: 3, Kept: 2, Rejected: 1},
+		},
+		{
+			name:          "reject writes failing rows with the line, target and error",
+			recipe:        "1 <- 1\n2 <- 2 -> readDateF(\"2006-01-02\")\n",
+			input:         "name,date\na,2021-01-02\nb,01/02/2021\nc,2021-03-04\n",
+			processHeader: true,
+			onError:       Reject,
+			want:          "name,date\na,2021-01-02T00:00:00Z\nc,2021-03-04T00:00:00Z\n",
+			wantRejects:   "name,date,line,target,error\nb,01/02/2021,3,column 2,readdatef(): unrecognized date '01/02/2021' for format: '2006-01-02'\n",
+			wantResult:    TransformationResult{HeaderLines: 1, Lines: 3, Kept: 2, Rejected: 1},
+		},
+		{
+			name:        "ragged rows fail by default",
+			recipe:      "1 <- 2\n",
+			input:       "a,1\nb\nc,3\n",
+			onError:     Fail,
+			want:        "1\n",
+			wantErrText: "record on line 2: wrong number of fields",
+		},
+		{
+			name:        "ragged rows are rejected as input errors",
+			recipe:      "1 <- 2\n",
+			input:       "a,1\nb\nc,3\nd,4,5\n",
+			onError:     Reject,
+			want:        "1\n3\n",
+			wantRejects: "b,2,input,record on line 2: wrong number of fields\nd,4,5,4,input,record on line 4: wrong number of fields\n",
+			wantResult:  TransformationResult{Lines: 4, Kept: 2, Rejected: 2},
+		},
+		{
+			name:          "header errors always fail",
+			recipe:        "1 <- 1\n!1 <- add(1, 1)\n",
+			input:         "a\n1\n",
+			processHeader: true,
+			onError:       Skip,
+			wantErrText:   "line 1 / header 1: add(): first arg to Add was not numeric: a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformation, err := Parse(strings.NewReader(tt.recipe))
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+
+			for _, workers := range []int{1, 4} {
+				var b, rejects bytes.Buffer
+				options := ExecuteOptions{Workers: workers, OnError: tt.onError, Rejects: csv.NewWriter(&rejects)}
+				result, err := transformation.ExecuteWithOptions(csv.NewReader(strings.NewReader(tt.input)), csv.NewWriter(&b), tt.processHeader, -1, options)
+				if tt.wantErrText != "" {
+					if err == nil || err.Error() != tt.wantErrText {
+						t.Errorf("workers %d: error = %v, want %v", workers, err, tt.wantErrText)
+					}
+				} else if err != nil {
+					t.Errorf("workers %d: execute error = %v", workers, err)
+				} else if !reflect.DeepEqual(*result, tt.wantResult) {
+					t.Errorf("workers %d: result = %+v, want %+v", workers, *result, tt.wantResult)
+				}
+				if got := b.String(); got != tt.want {
+					t.Errorf("workers %d: output = %v, want %v", workers, got, tt.want)
+				}
+				if got := rejects.String(); got != tt.wantRejects {
+					t.Errorf("workers %d: rejects = %v, want %v", workers, got, tt.wantRejects)
+				}
+			}
+		})
+	}
+}
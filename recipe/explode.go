@@ -0,0 +1,53 @@
+package recipe
+
+import (
+	"errors"
+	"strings"
+)
+
+// explosion collects how many rows a line of input is exploded into. Every call to explode raises the count to the
+// number of parts it found, so a row with two exploded columns gets as many rows as the longer of the two.
+type explosion struct {
+	rows int
+}
+
+// Explode splits the input on the separator and returns the part for the row being built. Rows past the number of
+// parts in this input get an empty value. The header is never exploded, so it gets the input unchanged.
+func Explode(ctx LineContext, separator string, input string) (string, error) {
+	if separator == "" {
+		return "", errors.New("separator cannot be empty")
+	}
+	if ctx.explosion == nil || ctx.SubIndex == 0 {
+		return input, nil
+	}
+
+	parts := strings.Split(input, separator)
+	if len(parts) > ctx.explosion.rows {
+		ctx.explosion.rows = len(parts)
+	}
+	if ctx.SubIndex > len(parts) {
+		return "", nil
+	}
+	return parts[ctx.SubIndex-1], nil
+}
+
+// explodes reports whether the recipe explodes lines into several rows.
+func (t *Transformation) explodes() bool {
+	for _, r := range t.allRecipes() {
+		if t.recipeExplodes(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// recipeExplodes reports whether the recipe of a single variable, column or filter calls explode.
+func (t *Transformation) recipeExplodes(r Recipe) bool {
+	var found bool
+	forEachOperation(r.Pipe, func(o Operation) {
+		if f, ok := t.registry().Lookup(o.Name); ok && f.Name == "explode" {
+			found = true
+		}
+	})
+	return found
+}
@@ -0,0 +1,244 @@
+package recipe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// expression is a node of the tree a recipe is parsed into before it is compiled into the operations of a pipe.
+//
+//	expression := term { ( "->" | "+" ) term }
+//	term       := column | @name | literal | $variable | ? | call | "(" expression ")"
+//	call       := function [ "(" [ expression { "," expression } ] ")" ]
+//
+// Pipes and joins are applied left to right, parentheses group a part of the recipe so it is worked out on its own.
+type expression interface {
+	// compile returns the operations that work out the value of the expression.
+	compile() []Operation
+	// String returns the expression as it would be written in a recipe.
+	String() string
+}
+
+// valueExpression is a single value, like a column, a literal or the placeholder.
+type valueExpression struct {
+	arg  Argument
+	text string // as it was written in the recipe
+}
+
+func (e valueExpression) compile() []Operation {
+	return []Operation{{Name: "value", Arguments: []Argument{e.arg}}}
+}
+
+func (e valueExpression) String() string {
+	return e.text
+}
+
+// callExpression is a call to a function, with or without parentheses.
+type callExpression struct {
+	function *Function
+	name     string
+	parens   bool
+	args     []expression
+}
+
+func (e callExpression) compile() []Operation {
+	if !e.parens {
+		// the function gets the placeholder for every argument
+		args := []Argument{}
+		for i := 0; i < len(e.function.Args); i++ {
+			args = append(args, placeholderArg())
+		}
+		return []Operation{getFunction(e.name, args)}
+	}
+
+	var gotPlaceholder bool // track if the placeholder was explicitly provided or not
+	var args []Argument
+	for _, a := range e.args {
+		arg := argument(a)
+		if arg.Type == Placeholder {
+			gotPlaceholder = true
+		}
+		args = append(args, arg)
+	}
+	if !gotPlaceholder || len(args) == 0 {
+		args = append(args, placeholderArg())
+	}
+	return []Operation{getFunction(e.name, args)}
+}
+
+func (e callExpression) String() string {
+	if !e.parens {
+		return e.name
+	}
+	args := make([]string, len(e.args))
+	for i, a := range e.args {
+		args[i] = a.String()
+	}
+	return e.name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// pipeExpression passes the value of the left side to the right side as the placeholder.
+type pipeExpression struct {
+	left, right expression
+}
+
+func (e pipeExpression) compile() []Operation {
+	return append(e.left.compile(), e.right.compile()...)
+}
+
+func (e pipeExpression) String() string {
+	return e.left.String() + " -> " + e.right.String()
+}
+
+// joinExpression joins the value of the right side to the end of the left side. The right side gets the left side as
+// the placeholder.
+type joinExpression struct {
+	left, right expression
+}
+
+func (e joinExpression) compile() []Operation {
+	ops := append(e.left.compile(), getJoinWithPlaceholder())
+	return append(ops, e.right.compile()...)
+}
+
+func (e joinExpression) String() string {
+	return e.left.String() + " + " + e.right.String()
+}
+
+// groupExpression is an expression in parentheses, which is worked out on its own and then used as a single value.
+type groupExpression struct {
+	inner expression
+}
+
+func (e groupExpression) compile() []Operation {
+	return []Operation{{Name: "value", Arguments: []Argument{argument(e)}}}
+}
+
+func (e groupExpression) String() string {
+	return "(" + e.inner.String() + ")"
+}
+
+// argument returns the argument for an expression. Single values are used as they are, anything else is compiled
+// into a pipe of its own that is run with the placeholder of the operation the argument belongs to.
+func argument(e expression) Argument {
+	switch e := e.(type) {
+	case valueExpression:
+		return e.arg
+	case groupExpression:
+		return Argument{Type: Expression, Value: e.String(), Pipe: e.inner.compile()}
+	}
+	return Argument{Type: Expression, Value: e.String(), Pipe: e.compile()}
+}
+
+// expressionParser reads the expression on the right side of a recipe line.
+type expressionParser struct {
+	p        *Parser
+	registry *FunctionRegistry
+}
+
+// parseExpression reads a pipe of terms joined by -> and +.
+func (ep *expressionParser) parseExpression() (expression, error) {
+	left, err := ep.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, _ := ep.p.scanIgnoreWhitespace()
+		switch tok {
+		case PIPE, PLUS:
+			right, err := ep.parseTerm()
+			if err != nil {
+				return nil, err
+			}
+			if tok == PIPE {
+				left = pipeExpression{left, right}
+			} else {
+				left = joinExpression{left, right}
+			}
+		default:
+			ep.p.unscan()
+			return left, nil
+		}
+	}
+}
+
+// parseTerm reads a single value, a function call or an expression in parentheses.
+func (ep *expressionParser) parseTerm() (expression, error) {
+	tok, lit := ep.p.scanIgnoreWhitespace()
+	switch tok {
+	case COLUMN_ID:
+		return valueExpression{columnArg(lit), lit}, nil
+	case LITERAL:
+		return valueExpression{literalArg(lit), fmt.Sprintf("%q", lit)}, nil
+	case VARIABLE:
+		return valueExpression{variableArg(lit), lit}, nil
+	case DIRECTIVE, COLUMN_NAME:
+		name := columnName(tok, lit)
+		return valueExpression{namedColumnArg(name), ColumnNameRef(name)}, nil
+	case PLACEHOLDER:
+		return valueExpression{placeholderArg(), "?"}, nil
+	case FUNCTION:
+		return ep.parseCall(lit)
+	case OPEN_PAREN:
+		inner, err := ep.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if tok, lit := ep.p.scanIgnoreWhitespace(); tok != CLOSE_PAREN {
+			return nil, fmt.Errorf("expected ) to close (%s, but found %s", inner, describeToken(tok, lit))
+		}
+		return groupExpression{inner}, nil
+	}
+	return nil, fmt.Errorf("expected a column, literal, variable, function or (, but found %s", describeToken(tok, lit))
+}
+
+// parseCall reads the arguments of a function, if it has parentheses right after its name.
+func (ep *expressionParser) parseCall(name string) (expression, error) {
+	function, ok := ep.registry.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unrecognized function %s", name)
+	}
+	call := callExpression{function: function, name: name}
+	if tok, _ := ep.p.scan(); tok != OPEN_PAREN {
+		ep.p.unscan()
+		return call, nil
+	}
+	call.parens = true
+
+	if tok, _ := ep.p.scanIgnoreWhitespace(); tok == CLOSE_PAREN {
+		return call, nil
+	}
+	ep.p.unscan()
+	for {
+		arg, err := ep.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		// a bare number is a number, not a column, where the function expects one
+		if v, ok := arg.(valueExpression); ok && v.arg.Type == Column && function.isNumberArg(len(call.args)) {
+			v.arg = literalArg(v.arg.Value)
+			arg = v
+		}
+		call.args = append(call.args, arg)
+
+		tok, lit := ep.p.scanIgnoreWhitespace()
+		switch tok {
+		case COMMA:
+			continue
+		case CLOSE_PAREN:
+			return call, nil
+		}
+		return nil, fmt.Errorf("expected , or ) in the arguments of %s, but found %s", name, describeToken(tok, lit))
+	}
+}
+
+// describeToken names a token for an error message.
+func describeToken(tok Token, lit string) string {
+	switch tok {
+	case EOF:
+		return "the end of the line"
+	case LITERAL:
+		return fmt.Sprintf("%q", lit)
+	}
+	return fmt.Sprintf("'%s'", lit)
+}
@@ -0,0 +1,72 @@
+package recipe
+
+import (
+	"bytes"
+	encodingcsv "encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestTransformation_ExecuteExpressions(t *testing.T) {
+	input := "first,last\nann,smith\n"
+
+	tests := []struct {
+		name   string
+		recipe string
+		want   string
+	}{
+		{
+			name:   "nested function calls",
+			recipe: "1 <- uppercase(firstChars(\"1\", 2))\n",
+			want:   "first\nS\n",
+		},
+		{
+			name:   "join in an argument",
+			recipe: "1 <- uppercase(1 + \".\" + 2)\n",
+			want:   "first\nANN.SMITH\n",
+		},
+		{
+			name:   "group joined after a column",
+			recipe: "1 <- 1 + (2 -> uppercase)\n",
+			want:   "first\nannSMITH\n",
+		},
+		{
+			name:   "group gets the placeholder",
+			recipe: "1 <- 2 -> (uppercase + \"-\" + 1)\n",
+			want:   "first\nSMITH-ann\n",
+		},
+		{
+			name:   "flat recipes run left to right",
+			recipe: "1 <- 1 + \" \" + 2 -> uppercase\n",
+			want:   "first\nANN SMITH\n",
+		},
+		{
+			name:   "named columns and variables in arguments",
+			recipe: "$last <- @last\n1 <- lowercase(uppercase($last) + @first)\n",
+			want:   "first\nsmithann\n",
+		},
+		{
+			name:   "statement over several lines",
+			recipe: "1 <- 1 ->\n  uppercase +\n  # a dash\n  \"-\" +\n  lowercase(\n    2\n  )\n",
+			want:   "first\nANN-smith\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformation, err := Parse(strings.NewReader(tt.recipe))
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+			var out bytes.Buffer
+			writer := encodingcsv.NewWriter(&out)
+			_, err = transformation.Execute(encodingcsv.NewReader(strings.NewReader(input)), writer, true, -1)
+			if err != nil {
+				t.Fatalf("execute error = %v", err)
+			}
+			writer.Flush()
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
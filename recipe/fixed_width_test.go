@@ -0,0 +1,48 @@
+package recipe
+
+import (
+	"bytes"
+	encodingcsv "encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/dstockto/csv-chef/csv"
+)
+
+func TestTransformation_ExecuteFixedWidth(t *testing.T) {
+	input, err := csv.ReadLayout(strings.NewReader("name,start,length,align,pad\nid,1,5,right,0\nname,6,8\n"))
+	if err != nil {
+		t.Fatalf("layout error = %v", err)
+	}
+	output, err := csv.ReadLayout(strings.NewReader("name,start,length\nname,1,4\nid,5,3\n"))
+	if err != nil {
+		t.Fatalf("layout error = %v", err)
+	}
+	transformation, err := Parse(strings.NewReader("1 <- @name\n2 <- @id\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+
+	for _, workers := range []int{1, 4} {
+		reader := csv.NewFixedWidthReader(strings.NewReader("00001Bartholo\n\n00042Ann\n"), input)
+		var out, rejects bytes.Buffer
+		writer := csv.NewFixedWidthWriter(&out, output, csv.FixedWidthOptions{})
+		options := ExecuteOptions{Workers: workers, OnError: Reject, Rejects: encodingcsv.NewWriter(&rejects)}
+
+		result, err := transformation.ExecuteWithOptions(reader, writer, true, -1, options)
+		if err != nil {
+			t.Fatalf("workers %d: execute error = %v", workers, err)
+		}
+		writer.Flush()
+		if want := "Ann 42 \n"; out.String() != want {
+			t.Errorf("workers %d: output = %q, want %q", workers, out.String(), want)
+		}
+		wantRejects := "id,name,line,target,error\n1,Bartholo,2,output,value 'Bartholo' is longer than the 4 characters of field name\n"
+		if got := rejects.String(); got != wantRejects {
+			t.Errorf("workers %d: rejects = %q, want %q", workers, got, wantRejects)
+		}
+		if result.Rejected != 1 {
+			t.Errorf("workers %d: rejected = %d, want 1", workers, result.Rejected)
+		}
+	}
+}
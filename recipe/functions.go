@@ -0,0 +1,133 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FunctionImpl is the Go implementation of a recipe function. It receives the context for the line being processed
+// and the evaluated arguments, with any missing arguments already filled in with the placeholder value.
+type FunctionImpl func(ctx LineContext, args []string) (string, error)
+
+// Function describes a function that can be called from a recipe.
+type Function struct {
+	Name        string
+	Aliases     []string
+	Args        []string // names of the arguments, used for documentation and to determine the arity
+	Description string
+	Impl        FunctionImpl
+	// Aggregate makes the function an aggregate, which can only be the last step of a column recipe. Impl returns
+	// the value to aggregate for each line and the values for a group are combined by an Aggregator.
+	Aggregate AggregatorFactory
+	// Variadic functions accept any number of arguments and are passed all of them. Args only documents them.
+	Variadic bool
+	// NumberArgs lists the positions, starting at 0, of arguments where a bare number is the number itself instead
+	// of a column reference, ex: the column of a lookup table.
+	NumberArgs []int
+}
+
+func (f *Function) isNumberArg(position int) bool {
+	for _, p := range f.NumberArgs {
+		if p == position {
+			return true
+		}
+	}
+	return false
+}
+
+// Signature returns the function name with its argument names, ex: change(from, to, input)
+func (f *Function) Signature() string {
+	return fmt.Sprintf("%s(%s)", f.Name, strings.Join(f.Args, ", "))
+}
+
+// FunctionRegistry holds the functions that are available to recipes. Function names and aliases are
+// case-insensitive.
+type FunctionRegistry struct {
+	functions map[string]*Function
+	ordered   []*Function
+}
+
+// reservedNames are operations the parser generates on its own that cannot be replaced by a registered function.
+var reservedNames = map[string]bool{
+	"value": true,
+}
+
+func NewFunctionRegistry() *FunctionRegistry {
+	return &FunctionRegistry{
+		functions: make(map[string]*Function),
+	}
+}
+
+// Register adds a function to the registry. It is an error to register a function whose name or alias is
+// already taken.
+func (r *FunctionRegistry) Register(f Function) error {
+	if f.Name == "" {
+		return errors.New("function name is required")
+	}
+	if f.Impl == nil {
+		return fmt.Errorf("function %s has no implementation", f.Name)
+	}
+
+	names := append([]string{f.Name}, f.Aliases...)
+	for _, name := range names {
+		key := strings.ToLower(name)
+		if reservedNames[key] {
+			return fmt.Errorf("function name %s is reserved", name)
+		}
+		if _, ok := r.functions[key]; ok {
+			return fmt.Errorf("function %s already registered", name)
+		}
+	}
+
+	function := f
+	for _, name := range names {
+		r.functions[strings.ToLower(name)] = &function
+	}
+	r.ordered = append(r.ordered, &function)
+
+	return nil
+}
+
+// MustRegister is like Register but panics if the function cannot be registered.
+func (r *FunctionRegistry) MustRegister(f Function) {
+	if err := r.Register(f); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup finds a function by name or alias.
+func (r *FunctionRegistry) Lookup(name string) (*Function, bool) {
+	f, ok := r.functions[strings.ToLower(name)]
+	return f, ok
+}
+
+// Functions returns all the registered functions sorted by name.
+func (r *FunctionRegistry) Functions() []*Function {
+	functions := make([]*Function, len(r.ordered))
+	copy(functions, r.ordered)
+	sort.Slice(functions, func(i, j int) bool {
+		return strings.ToLower(functions[i].Name) < strings.ToLower(functions[j].Name)
+	})
+	return functions
+}
+
+// DefaultRegistry contains the built-in functions and is used for any recipe that is not parsed with its own
+// registry.
+var DefaultRegistry = NewBuiltinRegistry()
+
+// RegisterFunction adds a function to the DefaultRegistry so that it is available to all recipes.
+func RegisterFunction(f Function) error {
+	return DefaultRegistry.Register(f)
+}
+
+// NewBuiltinRegistry returns a new registry loaded with the built-in functions. Embedders can use this to add
+// their own functions without changing the DefaultRegistry.
+func NewBuiltinRegistry() *FunctionRegistry {
+	r := NewFunctionRegistry()
+	for _, f := range builtinFunctions {
+		r.MustRegister(f)
+	}
+	return r
+}
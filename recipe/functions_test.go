@@ -0,0 +1,184 @@
+package recipe
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestFunctionRegistry_Register(t *testing.T) {
+	impl := func(ctx LineContext, args []string) (string, error) { return args[0], nil }
+
+	tests := []struct {
+		name        string
+		functions   []Function
+		wantErr     bool
+		wantErrText string
+	}{
+		{
+			name:      "register a function",
+			functions: []Function{{Name: "echo", Args: []string{"?"}, Impl: impl}},
+		},
+		{
+			name:        "function name is required",
+			functions:   []Function{{Args: []string{"?"}, Impl: impl}},
+			wantErr:     true,
+			wantErrText: "function name is required",
+		},
+		{
+			name:        "function must have an implementation",
+			functions:   []Function{{Name: "echo", Args: []string{"?"}}},
+			wantErr:     true,
+			wantErrText: "function echo has no implementation",
+		},
+		{
+			name: "function names are unique regardless of case",
+			functions: []Function{
+				{Name: "echo", Args: []string{"?"}, Impl: impl},
+				{Name: "ECHO", Args: []string{"?"}, Impl: impl},
+			},
+			wantErr:     true,
+			wantErrText: "function ECHO already registered",
+		},
+		{
+			name: "aliases cannot collide with names",
+			functions: []Function{
+				{Name: "echo", Args: []string{"?"}, Impl: impl},
+				{Name: "shout", Aliases: []string{"echo"}, Args: []string{"?"}, Impl: impl},
+			},
+			wantErr:     true,
+			wantErrText: "function echo already registered",
+		},
+		{
+			name:        "value is reserved for the parser",
+			functions:   []Function{{Name: "value", Args: []string{"?"}, Impl: impl}},
+			wantErr:     true,
+			wantErrText: "function name value is reserved",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewFunctionRegistry()
+			var err error
+			for _, f := range tt.functions {
+				if err = r.Register(f); err != nil {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Register() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && err.Error() != tt.wantErrText {
+				t.Errorf("got error text = %v, want error text = %v", err.Error(), tt.wantErrText)
+			}
+		})
+	}
+}
+
+func TestFunctionRegistry_Lookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		lookup   string
+		wantName string
+		wantOk   bool
+	}{
+		{name: "lookup by name", lookup: "uppercase", wantName: "uppercase", wantOk: true},
+		{name: "lookup is case-insensitive", lookup: "firstChars", wantName: "firstchars", wantOk: true},
+		{name: "lookup by alias", lookup: "isEmpty", wantName: "ifempty", wantOk: true},
+		{name: "unknown function", lookup: "if_after", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DefaultRegistry.Lookup(tt.lookup)
+			if ok != tt.wantOk {
+				t.Errorf("Lookup() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if ok && got.Name != tt.wantName {
+				t.Errorf("Lookup() = %v, want %v", got.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestFunctionRegistry_Functions(t *testing.T) {
+	r := NewFunctionRegistry()
+	impl := func(ctx LineContext, args []string) (string, error) { return "", nil }
+	r.MustRegister(Function{Name: "zebra", Impl: impl})
+	r.MustRegister(Function{Name: "Apple", Aliases: []string{"pomme"}, Impl: impl})
+	r.MustRegister(Function{Name: "mango", Impl: impl})
+
+	var names []string
+	for _, f := range r.Functions() {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, ","); got != "Apple,mango,zebra" {
+		t.Errorf("Functions() = %v, want %v", got, "Apple,mango,zebra")
+	}
+}
+
+func TestParseWithRegistry(t *testing.T) {
+	registry := NewBuiltinRegistry()
+	registry.MustRegister(Function{
+		Name:    "surround",
+		Aliases: []string{"wrap"},
+		Args:    []string{"with", "?"},
+		Impl: func(ctx LineContext, args []string) (string, error) {
+			return args[0] + args[1] + args[0], nil
+		},
+	})
+
+	tests := []struct {
+		name             string
+		recipe           string
+		input            string
+		want             string
+		wantParseErr     bool
+		wantParseErrText string
+	}{
+		{
+			name:   "custom function with placeholder filled in",
+			recipe: "1 <- 1 -> surround(\"*\") -> uppercase\n2 <- wrap(\"_\", 2)\n",
+			input:  "a,b\nc,d\n",
+			want:   "*A*,_b_\n*C*,_d_\n",
+		},
+		{
+			name:             "unknown function is a parse error",
+			recipe:           "1 <- 1 -> shout\n",
+			wantParseErr:     true,
+			wantParseErrText: "error - line 1: unrecognized function shout",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformation, err := ParseWithRegistry(strings.NewReader(tt.recipe), registry)
+			if (err != nil) != tt.wantParseErr {
+				t.Errorf("parse error = %v, wantErr %v", err, tt.wantParseErr)
+				return
+			}
+			if tt.wantParseErr {
+				if err.Error() != tt.wantParseErrText {
+					t.Errorf("got parse error text = %v, want error text = %v", err.Error(), tt.wantParseErrText)
+				}
+				return
+			}
+
+			var b bytes.Buffer
+			_, err = transformation.Execute(csv.NewReader(strings.NewReader(tt.input)), csv.NewWriter(&b), false, -1)
+			if err != nil {
+				t.Errorf("execute error = %v", err)
+				return
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// the default registry does not see functions registered elsewhere
+	if _, err := Parse(strings.NewReader("1 <- surround(\"*\")")); err == nil {
+		t.Errorf("expected surround to be unrecognized in the default registry")
+	}
+}
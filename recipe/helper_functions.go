@@ -8,6 +8,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"syreclabs.com/go/faker"
 	"time"
 )
 
@@ -305,6 +307,180 @@ func IfEmpty(emptyVal string, notEmptyVal string, input string) (string, error)
 	return notEmptyVal, nil
 }
 
+// IsTrue reports whether a value counts as true for filters. Empty values, "0" and "false" (in any case) are false,
+// everything else is true.
+func IsTrue(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "0", "false":
+		return false
+	}
+	return true
+}
+
+func boolString(b bool) string {
+	return strconv.FormatBool(b)
+}
+
+// Eq returns true if the input is the same as the value. Numbers are compared as numbers, so 1.0 is the same as 1.
+func Eq(value string, input string) (string, error) {
+	return boolString(compareValues(input, value) == 0), nil
+}
+
+// Ne returns true if the input is not the same as the value.
+func Ne(value string, input string) (string, error) {
+	return boolString(compareValues(input, value) != 0), nil
+}
+
+// Gt returns true if the input is greater than the value.
+func Gt(value string, input string) (string, error) {
+	return boolString(compareValues(input, value) > 0), nil
+}
+
+// Lt returns true if the input is less than the value.
+func Lt(value string, input string) (string, error) {
+	return boolString(compareValues(input, value) < 0), nil
+}
+
+// Ge returns true if the input is greater than or the same as the value.
+func Ge(value string, input string) (string, error) {
+	return boolString(compareValues(input, value) >= 0), nil
+}
+
+// Le returns true if the input is less than or the same as the value.
+func Le(value string, input string) (string, error) {
+	return boolString(compareValues(input, value) <= 0), nil
+}
+
+// compareValues returns -1, 0 or 1 comparing numerically if both values are numbers, otherwise as strings.
+func compareValues(x string, y string) int {
+	xnum, xerr := strconv.ParseFloat(x, 64)
+	ynum, yerr := strconv.ParseFloat(y, 64)
+	if xerr == nil && yerr == nil {
+		switch {
+		case xnum < ynum:
+			return -1
+		case xnum > ynum:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(x, y)
+}
+
+func Contains(search string, input string) (string, error) {
+	return boolString(strings.Contains(input, search)), nil
+}
+
+func StartsWith(prefix string, input string) (string, error) {
+	return boolString(strings.HasPrefix(input, prefix)), nil
+}
+
+func EndsWith(suffix string, input string) (string, error) {
+	return boolString(strings.HasSuffix(input, suffix)), nil
+}
+
+// maxPatterns is how many compiled regular expressions are kept. A pattern is usually the same for every row, but it
+// can be built from the rows, so the cache is kept small and forgets a pattern when it is full.
+const maxPatterns = 128
+
+// patterns caches compiled regular expressions, since the same pattern is usually used for every row.
+var patterns = struct {
+	sync.Mutex
+	compiled map[string]*regexp.Regexp
+}{compiled: make(map[string]*regexp.Regexp)}
+
+// compilePattern returns the compiled regular expression for the pattern, from the cache when it is there.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patterns.Lock()
+	defer patterns.Unlock()
+	if re, ok := patterns.compiled[pattern]; ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %v", pattern, err)
+	}
+	if len(patterns.compiled) >= maxPatterns {
+		for p := range patterns.compiled {
+			delete(patterns.compiled, p)
+			break
+		}
+	}
+	patterns.compiled[pattern] = re
+	return re, nil
+}
+
+// Matches returns true if the regular expression matches anywhere in the input.
+func Matches(pattern string, input string) (string, error) {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return "", err
+	}
+	return boolString(re.MatchString(input)), nil
+}
+
+func And(value string, input string) (string, error) {
+	return boolString(IsTrue(value) && IsTrue(input)), nil
+}
+
+func Or(value string, input string) (string, error) {
+	return boolString(IsTrue(value) || IsTrue(input)), nil
+}
+
+func Not(input string) (string, error) {
+	return boolString(!IsTrue(input)), nil
+}
+
+// When returns then if the condition is true, otherwise it returns otherwise. See IsTrue.
+func When(condition string, then string, otherwise string) (string, error) {
+	if IsTrue(condition) {
+		return then, nil
+	}
+	return otherwise, nil
+}
+
+// Case compares the input, which is the last arg, to each match in turn and returns the result paired with the
+// first one that is the same. The args before the input are match, result pairs, optionally followed by a default
+// that is returned when nothing matches. Without a default, the input is returned unchanged.
+func Case(args ...string) (string, error) {
+	if len(args) < 3 {
+		return "", errors.New("expected at least one match and result")
+	}
+	input := args[len(args)-1]
+	branches := args[:len(args)-1]
+	for i := 0; i+1 < len(branches); i += 2 {
+		if compareValues(input, branches[i]) == 0 {
+			return branches[i+1], nil
+		}
+	}
+	if len(branches)%2 == 1 {
+		return branches[len(branches)-1], nil
+	}
+	return input, nil
+}
+
+var fakers = map[string]func() string{
+	"name":      faker.Name().Name,
+	"firstname": faker.Name().FirstName,
+	"lastname":  faker.Name().LastName,
+	"address":   faker.Address().StreetAddress,
+	"city":      faker.Address().City,
+	"state":     faker.Address().State,
+	"zipcode":   faker.Address().ZipCode,
+	"phone":     faker.PhoneNumber().PhoneNumber,
+	"email":     faker.Internet().Email,
+	"company":   faker.Company().Name,
+}
+
+func Fake(kind string) (string, error) {
+	generator, ok := fakers[strings.ToLower(kind)]
+	if !ok {
+		return "", fmt.Errorf("unknown kind of fake data: '%s'", kind)
+	}
+	return generator(), nil
+}
+
 func MassProcess(incoming []string, processor Processor) (out []string) {
 	for _, s := range incoming {
 		out = append(out, processor(s))
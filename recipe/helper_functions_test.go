@@ -1,7 +1,9 @@
 package recipe
 
 import (
+	"fmt"
 	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -133,3 +135,135 @@ func TestNoDigits(t *testing.T) {
 		})
 	}
 }
+
+func TestFake(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		wantErr bool
+	}{
+		{name: "name", kind: "name"},
+		{name: "kind is case-insensitive", kind: "FirstName"},
+		{name: "zipcode", kind: "zipcode"},
+		{name: "unknown kind is an error", kind: "unicorn", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Fake(tt.kind)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Fake() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got == "" {
+				t.Errorf("Fake() returned an empty value for %s", tt.kind)
+			}
+		})
+	}
+}
+
+func TestIsTrue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "", want: false},
+		{value: "0", want: false},
+		{value: "false", want: false},
+		{value: " FALSE ", want: false},
+		{value: "true", want: true},
+		{value: "1", want: true},
+		{value: "no", want: true},
+		{value: "DEM", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := IsTrue(tt.value); got != tt.want {
+				t.Errorf("IsTrue(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComparisons(t *testing.T) {
+	tests := []struct {
+		name  string
+		fn    func(string, string) (string, error)
+		value string
+		input string
+		want  string
+	}{
+		{name: "eq strings", fn: Eq, value: "DEM", input: "DEM", want: "true"},
+		{name: "eq is case-sensitive", fn: Eq, value: "DEM", input: "dem", want: "false"},
+		{name: "eq numbers", fn: Eq, value: "1", input: "1.00", want: "true"},
+		{name: "ne", fn: Ne, value: "1", input: "2", want: "true"},
+		{name: "gt numbers", fn: Gt, value: "9", input: "10", want: "true"},
+		{name: "gt strings", fn: Gt, value: "9", input: "10a", want: "false"},
+		{name: "lt numbers", fn: Lt, value: "9", input: "-10", want: "true"},
+		{name: "ge same", fn: Ge, value: "5", input: "5.0", want: "true"},
+		{name: "le strings", fn: Le, value: "b", input: "a", want: "true"},
+		{name: "contains", fn: Contains, value: "ana", input: "banana", want: "true"},
+		{name: "startsWith", fn: StartsWith, value: "ban", input: "banana", want: "true"},
+		{name: "endsWith", fn: EndsWith, value: "ban", input: "banana", want: "false"},
+		{name: "matches", fn: Matches, value: `^\d{5}(-\d{4})?$`, input: "80202-1234", want: "true"},
+		{name: "does not match", fn: Matches, value: `^\d{5}$`, input: "8020", want: "false"},
+		{name: "and", fn: And, value: "true", input: "0", want: "false"},
+		{name: "or", fn: Or, value: "true", input: "0", want: "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn(tt.value, tt.input)
+			if err != nil {
+				t.Errorf("error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Matches("(", "a"); err == nil || err.Error() != "invalid pattern '(': error parsing regexp: missing closing ): `(`" {
+		t.Errorf("Matches() error = %v", err)
+	}
+}
+
+func TestMatches_CacheIsBounded(t *testing.T) {
+	for i := 0; i < maxPatterns*2; i++ {
+		if got, err := Matches(fmt.Sprintf("^%d$", i), strconv.Itoa(i)); err != nil || got != "true" {
+			t.Fatalf("Matches(^%d$) = %v, %v", i, got, err)
+		}
+	}
+	if n := len(patterns.compiled); n > maxPatterns {
+		t.Errorf("%d patterns cached, want at most %d", n, maxPatterns)
+	}
+}
+
+func TestCase(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		want        string
+		wantErrText string
+	}{
+		{name: "first match", args: []string{"DEM", "Democrat", "REP", "Republican", "DEM"}, want: "Democrat"},
+		{name: "second match", args: []string{"DEM", "Democrat", "REP", "Republican", "REP"}, want: "Republican"},
+		{name: "no match without default", args: []string{"DEM", "Democrat", "GRN"}, want: "GRN"},
+		{name: "no match with default", args: []string{"DEM", "Democrat", "Other", "GRN"}, want: "Other"},
+		{name: "numbers", args: []string{"1", "one", "2", "two", "2.0"}, want: "two"},
+		{name: "needs a match", args: []string{"default", "input"}, wantErrText: "expected at least one match and result"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Case(tt.args...)
+			if tt.wantErrText != "" {
+				if err == nil || err.Error() != tt.wantErrText {
+					t.Errorf("Case() error = %v, want %v", err, tt.wantErrText)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Case() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package recipe
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// includedFile is a recipe file that is being parsed.
+type includedFile struct {
+	path string // as it was named, relative to the working directory
+	abs  string // absolute path, to compare files however they were named
+}
+
+// isInclude reports whether a statement starting with the token includes another recipe, ex: @include "common.recipe"
+// The @ is optional.
+func isInclude(tok Token, lit string) bool {
+	return (tok == DIRECTIVE && strings.EqualFold(lit, "@include")) || (tok == FUNCTION && strings.EqualFold(lit, "include"))
+}
+
+// enter adds the file to the files being parsed, unless it is already being parsed, which would include it forever.
+func (rp *recipeParser) enter(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	for i, f := range rp.including {
+		if f.abs != abs {
+			continue
+		}
+		var cycle []string
+		for _, c := range rp.including[i:] {
+			cycle = append(cycle, c.path)
+		}
+		return fmt.Errorf("include cycle %s -> %s", strings.Join(cycle, " -> "), path)
+	}
+	rp.including = append(rp.including, includedFile{path: path, abs: abs})
+	return nil
+}
+
+// include reads the rest of an include statement and parses the recipe it names, found relative to the dir. Mistakes in
+// the included recipe are returned with the name of the file, a mistake in the statement itself is returned as an
+// error. Columns, headers and variables the included recipe defines can't be defined again.
+func (rp *recipeParser) include(p *Parser, dir string) (ParseErrors, error) {
+	name, err := expect(p, LITERAL, "quoted file path to include")
+	if err != nil {
+		return nil, err
+	}
+
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if err := rp.enter(path); err != nil {
+		return nil, err
+	}
+	defer func() { rp.including = rp.including[:len(rp.including)-1] }()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to include %s: %v", name, err)
+	}
+	defer f.Close()
+	if tok, lit := p.scanIgnoreWhitespace(); tok != EOF {
+		return nil, fmt.Errorf("unexpected %s after include", lit)
+	}
+
+	lookups := make(map[string]bool)
+	for l := range rp.t.Lookups {
+		lookups[l] = true
+	}
+	errs := rp.parse(f, path, filepath.Dir(path))
+
+	// lookup tables are found relative to the recipe that names them, not the one that is run
+	for l, lookup := range rp.t.Lookups {
+		if !lookups[l] && !filepath.IsAbs(lookup.Path) {
+			if abs, err := filepath.Abs(filepath.Join(filepath.Dir(path), lookup.Path)); err == nil {
+				lookup.Path = abs
+			}
+		}
+	}
+	return errs, nil
+}
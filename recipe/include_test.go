@@ -0,0 +1,108 @@
+package recipe
+
+import (
+	"bytes"
+	encodingcsv "encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFile_Include(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"common/names.recipe":  "# shared cleanup\n$first <- @first -> trim\n@lookup names = \"names.csv\" key 1\n",
+		"common/names.csv":     "name,full name\nann,Ann A\nbob,Bob B\n",
+		"main.recipe":          "@include \"common/names.recipe\"\n1 <- $first -> uppercase\n2 <- lookup(\"names\", 2, $first)\n",
+		"bare.recipe":          "include \"common/names.recipe\"\n1 <- $first\n",
+		"clash.recipe":         "@include \"common/names.recipe\"\n$first <- 2\n1 <- 1\n",
+		"cycle.recipe":         "@include \"common/cycle.recipe\"\n1 <- 1\n",
+		"common/cycle.recipe":  "@include \"../cycle.recipe\"\n",
+		"broken.recipe":        "@include \"common/broken.recipe\"\n1 <- 1\n@include \"missing.recipe\"\n",
+		"common/broken.recipe": "$ok <- 1\n$bad <- shout\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		recipe     string
+		want       string
+		wantErrors []string
+	}{
+		{
+			name:   "included variables and lookups",
+			recipe: "main.recipe",
+			want:   "first,last\nANN,Ann A\n",
+		},
+		{
+			name:   "without the @",
+			recipe: "bare.recipe",
+			want:   "first\nann\n",
+		},
+		{
+			name:       "defined again",
+			recipe:     "clash.recipe",
+			wantErrors: []string{"error - line 2: variable $first already defined"},
+		},
+		{
+			name:   "cycle",
+			recipe: "cycle.recipe",
+			wantErrors: []string{
+				"error - " + filepath.Join(dir, "common", "cycle.recipe") + " line 1: include cycle " +
+					filepath.Join(dir, "cycle.recipe") + " -> " + filepath.Join(dir, "common", "cycle.recipe") + " -> " +
+					filepath.Join(dir, "cycle.recipe"),
+			},
+		},
+		{
+			name:   "mistakes in the included file",
+			recipe: "broken.recipe",
+			wantErrors: []string{
+				"error - " + filepath.Join(dir, "common", "broken.recipe") + " line 2: unrecognized function shout",
+				"error - line 3: unable to include missing.recipe: open " + filepath.Join(dir, "missing.recipe") + ": no such file or directory",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformation, err := ParseFile(filepath.Join(dir, tt.recipe))
+			if tt.wantErrors != nil {
+				var errs ParseErrors
+				if !errors.As(err, &errs) {
+					t.Fatalf("ParseFile() error = %v, want ParseErrors", err)
+				}
+				var got []string
+				for _, e := range errs {
+					got = append(got, e.Error())
+				}
+				if strings.Join(got, "\n") != strings.Join(tt.wantErrors, "\n") {
+					t.Errorf("ParseFile() errors =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.wantErrors, "\n"))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+
+			var out bytes.Buffer
+			writer := encodingcsv.NewWriter(&out)
+			input := encodingcsv.NewReader(strings.NewReader("first,last\n ann ,x\n"))
+			if _, err := transformation.Execute(input, writer, true, -1); err != nil {
+				t.Fatalf("execute error = %v", err)
+			}
+			writer.Flush()
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package recipe
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dstockto/csv-chef/csv"
+)
+
+func TestTransformation_ExecuteJSONInput(t *testing.T) {
+	transformation, err := Parse(strings.NewReader("@fields \"id\" \"address\"\n@fields \"address.zip\"\n1 <- 1\n2 <- 2\n3 <- 3\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+
+	var b bytes.Buffer
+	writer, _ := csv.NewWriter(&b, csv.Dialect{})
+	reader := csv.NewJSONReader(strings.NewReader("{\"address\":{\"zip\":\"80202\"},\"id\":7}\n"), transformation.Fields)
+	if _, err := transformation.Execute(reader, writer, true, -1); err != nil {
+		t.Fatalf("execute error = %v", err)
+	}
+	writer.Flush()
+	want := "id,address,address.zip\n7,\"{\"\"zip\"\":\"\"80202\"\"}\",80202\n"
+	if got := b.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package recipe
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dstockto/csv-chef/csv"
+)
+
+// Lookup is a table of reference data loaded from a CSV file, used to fetch values from the row that matches a key.
+type Lookup struct {
+	Name       string
+	Path       string // path to the CSV file, relative paths are relative to the recipe
+	KeyColumn  int    // column of the lookup file holding the key, starting at 1
+	Default    string // value for keys that are not in the lookup file, used if HasDefault is set
+	HasDefault bool
+	NoHeader   bool // the first row of the file is data, otherwise it is a header and can't be looked up
+	rows       map[string][]string
+}
+
+// Load reads the lookup table, leaving out the header row unless there isn't one. If more than one row has the same
+// key, the first one is used.
+func (l *Lookup) Load(reader RowReader) error {
+	rows := make(map[string][]string)
+	header := !l.NoHeader
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("lookup %s: %v", l.Name, err)
+		}
+		if header {
+			header = false
+			continue
+		}
+		if len(row) < l.KeyColumn {
+			continue
+		}
+		key := row[l.KeyColumn-1]
+		if _, ok := rows[key]; !ok {
+			rows[key] = row
+		}
+	}
+	l.rows = rows
+	return nil
+}
+
+// Loaded reports whether the lookup table has been loaded.
+func (l *Lookup) Loaded() bool {
+	return l.rows != nil
+}
+
+// Get returns the value of a column from the row matching the key.
+func (l *Lookup) Get(key string, column int) (string, error) {
+	row, ok := l.rows[key]
+	if !ok {
+		if l.HasDefault {
+			return l.Default, nil
+		}
+		return "", fmt.Errorf("key '%s' not found in lookup %s", key, l.Name)
+	}
+	if column < 1 || column > len(row) {
+		return "", fmt.Errorf("lookup %s has no column %d for key '%s'", l.Name, column, key)
+	}
+	return row[column-1], nil
+}
+
+// LoadLookups loads every lookup table that has not been loaded yet, reading the files with the input dialect and
+// encoding. It is called by Execute, so it only needs to be called directly to find problems with the lookup files
+// before executing.
+func (t *Transformation) LoadLookups() error {
+	for _, l := range t.Lookups {
+		if l.Loaded() {
+			continue
+		}
+		path := l.Path
+		if !filepath.IsAbs(path) && t.Dir != "" {
+			path = filepath.Join(t.Dir, path)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("lookup %s: %v", l.Name, err)
+		}
+		err = t.loadLookup(l, f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Transformation) loadLookup(l *Lookup, r io.Reader) error {
+	encoding := t.InputEncoding
+	if encoding == "" {
+		encoding = "auto"
+	}
+	decoded, err := csv.NewDecoder(r, encoding)
+	if err != nil {
+		return fmt.Errorf("lookup %s: %v", l.Name, err)
+	}
+	reader, err := csv.NewReader(decoded, t.InputDialect)
+	if err != nil {
+		return fmt.Errorf("lookup %s: %v", l.Name, err)
+	}
+	reader.AllowAnyFieldCount()
+	return l.Load(reader)
+}
+
+// validateLookups makes sure every lookup called with a literal name has been declared.
+func (t *Transformation) validateLookups() error {
+	var err error
+	for _, r := range t.allRecipes() {
+		forEachOperation(r.Pipe, func(o Operation) {
+			if err != nil || !strings.EqualFold(o.Name, "lookup") || len(o.Arguments) == 0 || o.Arguments[0].Type != Literal {
+				return
+			}
+			if _, ok := t.Lookups[o.Arguments[0].Value]; !ok {
+				err = fmt.Errorf("lookup %s is not defined, add @lookup %s = \"file.csv\" key 1 to the recipe", o.Arguments[0].Value, o.Arguments[0].Value)
+			}
+		})
+	}
+	return err
+}
+
+// LookupValue fetches a column from the row of a lookup table that matches the key.
+func LookupValue(ctx LineContext, name string, column string, key string) (string, error) {
+	lookup, ok := ctx.lookups[name]
+	if !ok {
+		return "", fmt.Errorf("lookup %s is not defined", name)
+	}
+	columnNum, err := strconv.Atoi(column)
+	if err != nil {
+		return "", fmt.Errorf("lookup column was not a number: %s", column)
+	}
+	return lookup.Get(key, columnNum)
+}
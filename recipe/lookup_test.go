@@ -0,0 +1,161 @@
+package recipe
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTransformation_ExecuteLookup(t *testing.T) {
+	dir := t.TempDir()
+	parties := "code,name\nDEM,Democrat\nREP,Republican\nDEM,Duplicate\n"
+	if err := os.WriteFile(filepath.Join(dir, "parties.csv"), []byte(parties), 0644); err != nil {
+		t.Fatal(err)
+	}
+	zips := "Denver,80202\nBoulder,80301,Boulder County\n"
+	if err := os.WriteFile(filepath.Join(dir, "zips.csv"), []byte(zips), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		recipe           string
+		input            string
+		want             string
+		wantParseErrText string
+		wantErrText      string
+	}{
+		{
+			name:   "fetch a column from the matching row",
+			recipe: "@lookup parties = \"parties.csv\" key 1\n1 <- 1\n2 <- 2 -> lookup(\"parties\", 2)\n",
+			input:  "ann,DEM\nbob,REP\n",
+			want:   "ann,Democrat\nbob,Republican\n",
+		},
+		{
+			name:   "missing keys use the default",
+			recipe: "@lookup parties = \"parties.csv\" key 1 default \"Other\" # unknown parties\n1 <- lookup(\"parties\", 2, 2)\n",
+			input:  "ann,DEM\ncat,GRN\n",
+			want:   "Democrat\nOther\n",
+		},
+		{
+			name:   "the header row isn't a key",
+			recipe: "@lookup parties = \"parties.csv\" key 1 default \"Other\"\n1 <- lookup(\"parties\", 2, 2)\n",
+			input:  "ann,code\n",
+			want:   "Other\n",
+		},
+		{
+			name:   "without a header the first row is a key",
+			recipe: "@lookup zips = \"zips.csv\" key 2 noheader default \"none\"\n1 <- lookup(\"zips\", 1, 1)\n",
+			input:  "80202\n80203\n",
+			want:   "Denver\nnone\n",
+		},
+		{
+			name:        "missing keys without a default are errors",
+			recipe:      "@lookup parties = \"parties.csv\" key 1\n1 <- 2 -> lookup(\"parties\", 2, ?)\n",
+			input:       "ann,DEM\ncat,GRN\n",
+			wantErrText: "line 2 / column 1: lookup(): key 'GRN' not found in lookup parties",
+		},
+		{
+			name:   "key column and ragged rows",
+			recipe: "@lookup zips = \"zips.csv\" key 2 noheader\n1 <- 1\n2 <- 1 -> lookup(\"zips\", 1) + \" \" + lookup(\"zips\", 3, 1)\n",
+			input:  "80301\n",
+			want:   "80301,Boulder Boulder County\n",
+		},
+		{
+			name:        "columns past the end of the lookup row are errors",
+			recipe:      "@lookup zips = \"zips.csv\" key 2 noheader\n1 <- lookup(\"zips\", 3, 1)\n",
+			input:       "80202\n",
+			wantErrText: "line 1 / column 1: lookup(): lookup zips has no column 3 for key '80202'",
+		},
+		{
+			name:             "lookups must be declared",
+			recipe:           "1 <- 1 -> lookup(\"parties\", 2)\n",
+			wantParseErrText: "lookup parties is not defined, add @lookup parties = \"file.csv\" key 1 to the recipe",
+		},
+		{
+			name:             "lookups can only be declared once",
+			recipe:           "@lookup parties = \"parties.csv\" key 1\n@lookup parties = \"other.csv\" key 1\n1 <- 1\n",
+			wantParseErrText: "error - line 2: lookup parties already defined",
+		},
+		{
+			name:             "lookup needs a key",
+			recipe:           "@lookup parties = \"parties.csv\"\n1 <- 1\n",
+			wantParseErrText: "error - line 1: expected key but found EOF",
+		},
+		{
+			name:             "unknown directive",
+			recipe:           "@join parties\n1 <- 1\n",
+			wantParseErrText: "error - line 1: unrecognized directive @join",
+		},
+		{
+			name:        "missing lookup file",
+			recipe:      "@lookup parties = \"nope.csv\" key 1\n1 <- 1\n",
+			input:       "a\n",
+			wantErrText: "lookup parties: open " + filepath.Join(dir, "nope.csv") + ": no such file or directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipeFile := filepath.Join(dir, "recipe.txt")
+			if err := os.WriteFile(recipeFile, []byte(tt.recipe), 0644); err != nil {
+				t.Fatal(err)
+			}
+			transformation, err := ParseFile(recipeFile)
+			if tt.wantParseErrText != "" {
+				if err == nil || err.Error() != tt.wantParseErrText {
+					t.Errorf("parse error = %v, want %v", err, tt.wantParseErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+
+			for _, workers := range []int{1, 4} {
+				var b bytes.Buffer
+				options := ExecuteOptions{Workers: workers}
+				_, err := transformation.ExecuteWithOptions(csv.NewReader(strings.NewReader(tt.input)), csv.NewWriter(&b), false, -1, options)
+				if tt.wantErrText != "" {
+					if err == nil || err.Error() != tt.wantErrText {
+						t.Errorf("workers %d: error = %v, want %v", workers, err, tt.wantErrText)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("workers %d: execute error = %v", workers, err)
+					continue
+				}
+				if got := b.String(); got != tt.want {
+					t.Errorf("workers %d: output = %v, want %v", workers, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTransformation_LoadLookupsLikeTheInput(t *testing.T) {
+	// semicolons in UTF-16, with a byte order mark
+	dir := t.TempDir()
+	parties := "\xff\xfec\x00o\x00d\x00e\x00;\x00n\x00a\x00m\x00e\x00\n\x00D\x00E\x00M\x00;\x00D\x00e\x00m\x00\n\x00"
+	if err := os.WriteFile(filepath.Join(dir, "parties.csv"), []byte(parties), 0644); err != nil {
+		t.Fatal(err)
+	}
+	transformation, err := Parse(strings.NewReader("@input delimiter \";\"\n@lookup parties = \"parties.csv\" key 1\n1 <- 1 -> lookup(\"parties\", 2)\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+	transformation.Dir = dir
+	transformation.InputEncoding = "utf-16le"
+
+	var b bytes.Buffer
+	_, err = transformation.Execute(csv.NewReader(strings.NewReader("DEM\n")), csv.NewWriter(&b), false, -1)
+	if err != nil {
+		t.Fatalf("execute error = %v", err)
+	}
+	if want := "Dem\n"; b.String() != want {
+		t.Errorf("output = %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,50 @@
+package recipe
+
+import (
+	"bytes"
+	encodingcsv "encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dstockto/csv-chef/csv"
+)
+
+// openFiles opens the named strings as if they were files.
+func openFiles(files map[string]string) csv.OpenFunc {
+	return func(path string) (csv.RowReader, func() error, error) {
+		contents, ok := files[path]
+		if !ok {
+			return nil, nil, fmt.Errorf("no such file")
+		}
+		reader, err := csv.NewReader(strings.NewReader(contents), csv.Dialect{})
+		return reader, func() error { return nil }, err
+	}
+}
+
+func TestTransformation_ExecuteMultipleFiles(t *testing.T) {
+	files := map[string]string{
+		"a.csv":     "id,name\n1,Ann\n2,Bob\n",
+		"empty.csv": "",
+		"b.csv":     "name,id\nCal,3\n",
+	}
+	transformation, err := Parse(strings.NewReader("1 <- 1\n2 <- 2\n3 <- filename\n4 <- lineno\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+
+	for _, workers := range []int{1, 4} {
+		reader := csv.NewMultiReader([]string{"a.csv", "empty.csv", "b.csv"}, openFiles(files), true, true)
+		var out bytes.Buffer
+		writer := encodingcsv.NewWriter(&out)
+
+		if _, err := transformation.ExecuteWithOptions(reader, writer, true, -1, ExecuteOptions{Workers: workers}); err != nil {
+			t.Fatalf("workers %d: execute error = %v", workers, err)
+		}
+		writer.Flush()
+		want := "id,name,column 3,column 4\n1,Ann,a.csv,2\n2,Bob,a.csv,3\n3,Cal,b.csv,4\n"
+		if got := out.String(); got != want {
+			t.Errorf("workers %d: output = %q, want %q", workers, got, want)
+		}
+	}
+}
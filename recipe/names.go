@@ -0,0 +1,72 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var simpleName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// ColumnNameRef returns the recipe syntax to reference an input column by its header name, ex: @zipcode or
+// @"first name" for names that are not a single word.
+func ColumnNameRef(name string) string {
+	if simpleName.MatchString(name) {
+		return "@" + name
+	}
+	name = strings.ReplaceAll(name, `\`, `\\`)
+	name = strings.ReplaceAll(name, `"`, `\"`)
+	return `@"` + name + `"`
+}
+
+// columnNames returns the header names the recipe references, in the order they are first used.
+func (t *Transformation) columnNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, r := range t.allRecipes() {
+		forEachOperation(r.Pipe, func(o Operation) {
+			for _, a := range o.Arguments {
+				if a.Type == NamedColumn && !seen[a.Value] {
+					seen[a.Value] = true
+					names = append(names, a.Value)
+				}
+			}
+		})
+	}
+	return names
+}
+
+// resolveNames returns the column number for each header name the recipe references. Any name that is not in the
+// header, or that is in it more than once, is an error.
+func (t *Transformation) resolveNames(header []string) (map[string]int, error) {
+	positions := make(map[string][]int)
+	for i, h := range header {
+		if i == 0 {
+			h = strings.TrimPrefix(h, "\ufeff")
+		}
+		h = strings.TrimSpace(h)
+		positions[h] = append(positions[h], i+1)
+	}
+
+	names := make(map[string]int)
+	for _, name := range t.columnNames() {
+		switch len(positions[name]) {
+		case 0:
+			return nil, fmt.Errorf("column %s not found in the header", ColumnNameRef(name))
+		case 1:
+			names[name] = positions[name][0]
+		default:
+			return nil, fmt.Errorf("column %s is in the header more than once", ColumnNameRef(name))
+		}
+	}
+	return names, nil
+}
+
+// requireNoNames is used when there is no header, so any reference by header name is an error.
+func (t *Transformation) requireNoNames() error {
+	if names := t.columnNames(); len(names) > 0 {
+		return errors.New("columns can only be referenced by name, like " + ColumnNameRef(names[0]) + ", when the header is processed")
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package recipe
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestColumnNameRef(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "zipcode", want: "@zipcode"},
+		{name: "address_2", want: "@address_2"},
+		{name: "first name", want: `@"first name"`},
+		{name: `say "hi" \o/`, want: `@"say \"hi\" \\o/"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ColumnNameRef(tt.name)
+			if got != tt.want {
+				t.Errorf("ColumnNameRef() = %v, want %v", got, tt.want)
+			}
+
+			// the reference must parse back to the same name
+			transformation, err := Parse(strings.NewReader("1 <- " + got + "\n"))
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+			if arg := transformation.Columns[1].Pipe[0].Arguments[0]; arg.Type != NamedColumn || arg.Value != tt.name {
+				t.Errorf("parsed %v as %s %v", got, arg.Type, arg.Value)
+			}
+		})
+	}
+}
+
+func TestTransformation_ExecuteNamePastTheEndOfARow(t *testing.T) {
+	transformation, err := Parse(strings.NewReader("1 <- @a\n2 <- @b\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+	reader := csv.NewReader(strings.NewReader("a,b\n1,2\n3\n"))
+	reader.FieldsPerRecord = -1
+
+	_, err = transformation.Execute(reader, csv.NewWriter(&bytes.Buffer{}), true, -1)
+	want := "line 3 / column 2: column @b (2) referenced, but it does not exist in the input"
+	if err == nil || err.Error() != want {
+		t.Errorf("execute error = %v, want %v", err, want)
+	}
+}
+
+func TestTransformation_ExecuteNamesAtTheSameTime(t *testing.T) {
+	transformation, err := Parse(strings.NewReader("1 <- @name\n2 <- @zip\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+
+	// each execution finds the names in its own header
+	inputs := []string{"name,zip\nann,80202\n", "zip,name\n80202,ann\n"}
+	outputs := make([]bytes.Buffer, len(inputs))
+	errs := make([]error, len(inputs))
+	var wg sync.WaitGroup
+	for i := range inputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; n < 100 && errs[i] == nil; n++ {
+				outputs[i].Reset()
+				writer := csv.NewWriter(&outputs[i])
+				_, errs[i] = transformation.Execute(csv.NewReader(strings.NewReader(inputs[i])), writer, true, -1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	want := []string{"name,zip\nann,80202\n", "zip,name\nann,80202\n"}
+	for i := range inputs {
+		if errs[i] != nil {
+			t.Fatalf("execute error = %v", errs[i])
+		}
+		if got := outputs[i].String(); got != want[i] {
+			t.Errorf("output = %q, want %q", got, want[i])
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package recipe
+
+import (
+	"context"
+	"io"
+)
+
+// rowsPerWorker limits how far ahead of the writer the reader can get, so a single slow row does not cause the
+// whole input to be buffered while waiting on it.
+const rowsPerWorker = 64
+
+type rowJob struct {
+	seq    int
+	lineNo int
+	row    []string
+	err    error  // error from reading the row, the row is not transformed
+	offset int64  // input bytes consumed once the row was read
+	file   string // file the row was read from
+}
+
+type rowResult struct {
+	seq    int
+	lineNo int
+	rows   [][]string // the output rows
+	input  []string   // the input row, kept in case there was an error
+	err    error
+	offset int64
+}
+
+// executeParallel transforms rows on a pool of workers and writes the results in the order they were read. The
+// first error in input order stops the execution, the same as executeSerial, so the output written before an
+// error is identical as well.
+func (t *Transformation) executeParallel(ctx context.Context, run *execution, reader RowReader, writer RowWriter, linesRead int, lineLimit int, options ExecuteOptions, transformResult *TransformationResult, progress *progressReporter) (int, error) {
+	workers := options.Workers
+	jobs := make(chan rowJob, workers)
+	results := make(chan rowResult, workers)
+	slots := make(chan struct{}, workers*rowsPerWorker)
+	done := make(chan struct{})
+	defer close(done)
+
+	// reader
+	go func() {
+		defer close(jobs)
+		lineNo := linesRead
+		for seq := 0; ; seq++ {
+			if lineLimit > 0 && lineNo >= lineLimit {
+				return
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+			row, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			stop := err != nil && (options.OnError == Fail || !isRowReadError(err))
+			if !stop {
+				lineNo++
+			}
+			// the reader may be set to reuse its record, so the worker needs its own copy
+			job := rowJob{seq: seq, lineNo: lineNo, row: append([]string(nil), row...), err: err, offset: inputOffset(reader), file: inputFilename(reader)}
+			select {
+			case jobs <- job:
+			case <-done:
+				return
+			}
+			if stop {
+				return
+			}
+		}
+	}()
+
+	// workers
+	finished := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer func() { finished <- struct{}{} }()
+			for job := range jobs {
+				result := rowResult{seq: job.seq, lineNo: job.lineNo, input: job.row, err: job.err, offset: job.offset}
+				if job.err == nil {
+					result.rows, result.err = t.transformRow(run, job.row, job.lineNo, job.file)
+				}
+				select {
+				case results <- result:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		for i := 0; i < workers; i++ {
+			<-finished
+		}
+		close(results)
+	}()
+
+	// writer, puts the results back in input order
+	pending := make(map[int]rowResult)
+	next := 0
+	for result := range results {
+		pending[result.seq] = result
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-slots
+
+			if err := ctx.Err(); err != nil {
+				return linesRead, err
+			}
+			linesRead = r.lineNo
+			if r.err == nil {
+				r.err = checkRows(writer, r.rows, r.lineNo)
+			}
+			if r.err != nil {
+				if err := t.rejectRow(options, r.input, r.lineNo, r.err, transformResult); err != nil {
+					return linesRead, err
+				}
+			} else if err := writeRows(writer, r.rows, transformResult); err != nil {
+				return linesRead, err
+			}
+			if linesRead%100 == 0 {
+				writer.Flush()
+			}
+			progress.update(Progress{RowsRead: linesRead, RowsWritten: transformResult.HeaderLines + transformResult.Kept, BytesRead: r.offset})
+		}
+	}
+
+	// the reader stops early when cancelled, so the rows above may all have been written without an error
+	if err := ctx.Err(); err != nil {
+		return linesRead, err
+	}
+	return linesRead, nil
+}
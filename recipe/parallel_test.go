@@ -0,0 +1,111 @@
+package recipe
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransformation_ExecuteParallel(t *testing.T) {
+	var big strings.Builder
+	big.WriteString("id,name,amount\n")
+	for i := 1; i <= 2500; i++ {
+		_, _ = fmt.Fprintf(&big, "%d,name %d,%d.5\n", i, i, i*3)
+	}
+	var bigWithError strings.Builder
+	bigWithError.WriteString(big.String())
+	bigWithError.WriteString("2501,bad,apple\n")
+	bigWithError.WriteString("2502,good,12\n")
+
+	tests := []struct {
+		name          string
+		recipe        string
+		input         string
+		processHeader bool
+		lineLimit     int
+	}{
+		{
+			name:          "line numbers and values stay in input order",
+			recipe:        "1 <- lineno\n2 <- 2 -> uppercase\n3 <- add(3, \"1\") -> numberFormat(\"1\")\n",
+			input:         big.String(),
+			processHeader: true,
+			lineLimit:     -1,
+		},
+		{
+			name:          "line limit is honored",
+			recipe:        "1 <- lineno + \"-\" + 1\n",
+			input:         big.String(),
+			processHeader: true,
+			lineLimit:     1001,
+		},
+		{
+			name:      "no header processing",
+			recipe:    "$id <- 1\n1 <- $id + 2\n",
+			input:     big.String(),
+			lineLimit: -1,
+		},
+		{
+			name:          "filtered rows are counted the same",
+			recipe:        "1 <- 1\n? <- mod(1, \"3\") -> eq(\"0\")\n",
+			input:         big.String(),
+			processHeader: true,
+			lineLimit:     -1,
+		},
+		{
+			name:          "exploded rows stay together",
+			recipe:        "1 <- 1\n2 <- 2 -> explode(\" \") -> lowercase\n3 <- subindex\n",
+			input:         big.String(),
+			processHeader: true,
+			lineLimit:     -1,
+		},
+		{
+			name:          "errors report the first failing line",
+			recipe:        "1 <- 1\n2 <- add(3, \"1\")\n",
+			input:         bigWithError.String(),
+			processHeader: true,
+			lineLimit:     -1,
+		},
+		{
+			name:      "input errors are reported in order",
+			recipe:    "1 <- 1\n",
+			input:     "a,b\nc,d\ne\nf,g\n",
+			lineLimit: -1,
+		},
+		{
+			name:          "empty input",
+			recipe:        "1 <- 1\n",
+			processHeader: true,
+			lineLimit:     -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformation, err := Parse(strings.NewReader(tt.recipe))
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+
+			var serial bytes.Buffer
+			wantResult, wantErr := transformation.Execute(csv.NewReader(strings.NewReader(tt.input)), csv.NewWriter(&serial), tt.processHeader, tt.lineLimit)
+
+			for _, workers := range []int{2, 4, 16} {
+				var parallel bytes.Buffer
+				options := ExecuteOptions{Workers: workers}
+				gotResult, gotErr := transformation.ExecuteWithOptions(csv.NewReader(strings.NewReader(tt.input)), csv.NewWriter(&parallel), tt.processHeader, tt.lineLimit, options)
+
+				if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+					t.Errorf("workers %d: error = %v, want %v", workers, gotErr, wantErr)
+				}
+				if (gotResult == nil) != (wantResult == nil) || (gotResult != nil && !reflect.DeepEqual(*gotResult, *wantResult)) {
+					t.Errorf("workers %d: result = %+v, want %+v", workers, gotResult, wantResult)
+				}
+				if parallel.String() != serial.String() {
+					t.Errorf("workers %d: output differs from serial execution", workers)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package recipe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// paramPrefix starts the name of a variable that reads a parameter, ex: $param.batch
+const paramPrefix = "$param."
+
+// isParam reports whether the variable reads a parameter instead of a variable of the recipe.
+func isParam(variable string) bool {
+	return strings.HasPrefix(variable, paramPrefix)
+}
+
+// paramName returns the name of the parameter a variable reads, ex: batch for $param.batch
+func paramName(variable string) string {
+	return strings.TrimPrefix(variable, paramPrefix)
+}
+
+func (t *Transformation) setParam(name, value string) {
+	if t.Params == nil {
+		t.Params = make(map[string]string)
+	}
+	t.Params[name] = value
+}
+
+// validateParams makes sure every parameter the recipe reads has a value, either because it was set or because it
+// was declared with a default.
+func (t *Transformation) validateParams() error {
+	var err error
+	for _, r := range t.allRecipes() {
+		forEachOperation(r.Pipe, func(o Operation) {
+			for _, a := range o.Arguments {
+				if err != nil || a.Type != Variable || !isParam(a.Value) {
+					continue
+				}
+				if _, ok := t.Params[paramName(a.Value)]; !ok {
+					err = fmt.Errorf("parameter %s is not set, add @param %s default \"value\" to the recipe or set it when baking", paramName(a.Value), paramName(a.Value))
+				}
+			}
+		})
+	}
+	return err
+}
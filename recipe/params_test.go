@@ -0,0 +1,98 @@
+package recipe
+
+import (
+	"bytes"
+	encodingcsv "encoding/csv"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseWithOptions_Params(t *testing.T) {
+	if err := os.Setenv("CSV_CHEF_TEST_USER", "ann"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("CSV_CHEF_TEST_USER")
+
+	tests := []struct {
+		name             string
+		recipe           string
+		params           map[string]string
+		want             string
+		wantParseErrText string
+	}{
+		{
+			name:   "set and default",
+			recipe: "@param batch\n@param source default \"crm\"\n1 <- $param.batch + \"/\" + $param.source\n",
+			params: map[string]string{"batch": "2026Q3"},
+			want:   "2026Q3/crm\n2026Q3/crm\n",
+		},
+		{
+			name:   "set overrides the default",
+			recipe: "@param source default \"crm\" # where the rows came from\n1 <- $param.source\n",
+			params: map[string]string{"source": "erp"},
+			want:   "erp\nerp\n",
+		},
+		{
+			name:   "set without a declaration",
+			recipe: "1 <- 1 + \"-\" + $param.batch_2\n",
+			params: map[string]string{"batch_2": "b"},
+			want:   "id-b\n1-b\n",
+		},
+		{
+			name:   "environment variable",
+			recipe: "1 <- env(\"CSV_CHEF_TEST_USER\") + env(\"CSV_CHEF_TEST_UNSET\")\n",
+			want:   "ann\nann\n",
+		},
+		{
+			name:             "required parameter not set",
+			recipe:           "1 <- 1\n@param batch\n",
+			wantParseErrText: "error - line 2: parameter batch is required but was not set",
+		},
+		{
+			name:             "parameter declared twice",
+			recipe:           "@param batch default \"a\"\n@param batch default \"b\"\n1 <- 1\n",
+			wantParseErrText: "error - line 2: parameter batch already declared",
+		},
+		{
+			name:             "parameters can't be assigned",
+			recipe:           "$param.batch <- 1\n",
+			wantParseErrText: "error - line 1: $param.batch is a parameter, it can't be assigned",
+		},
+		{
+			name:             "only parameters have a dot in their name",
+			recipe:           "1 <- $a.b\n",
+			wantParseErrText: "error - line 1: expected ->, + or the end of the line after $a, but found '.'",
+		},
+		{
+			name:             "parameter not set or declared",
+			recipe:           "1 <- $param.batch\n",
+			wantParseErrText: "parameter batch is not set, add @param batch default \"value\" to the recipe or set it when baking",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformation, err := ParseWithOptions(strings.NewReader(tt.recipe), ParseOptions{Params: tt.params})
+			if tt.wantParseErrText != "" {
+				if err == nil || err.Error() != tt.wantParseErrText {
+					t.Fatalf("parse error = %v, want %s", err, tt.wantParseErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+
+			var out bytes.Buffer
+			writer := encodingcsv.NewWriter(&out)
+			_, err = transformation.Execute(encodingcsv.NewReader(strings.NewReader("id\n1\n")), writer, false, -1)
+			if err != nil {
+				t.Fatalf("execute error = %v", err)
+			}
+			writer.Flush()
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
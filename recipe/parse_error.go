@@ -0,0 +1,85 @@
+package recipe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError is a mistake in a recipe. It has the position of the mistake so it can be pointed out in the recipe.
+type ParseError struct {
+	File    string // included recipe the mistake is in, empty if it is in the recipe being parsed
+	Line    int    // line of the recipe, starting at 1, 0 if the mistake isn't on a single line
+	Column  int    // column of the token that was wrong, starting at 1
+	Source  string // the line of the recipe
+	Token   string // the token that was wrong, empty at the end of the line
+	Message string // what was wrong, usually what was expected and what was found instead
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+	if e.File != "" {
+		return fmt.Sprintf("error - %s line %d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("error - line %d: %s", e.Line, e.Message)
+}
+
+// Caret returns the line of the recipe with a caret under the column of the mistake on the line below it, or an empty
+// string if the mistake isn't on a single line.
+func (e *ParseError) Caret() string {
+	if e.Line == 0 {
+		return ""
+	}
+	var caret strings.Builder
+	for i, ch := range []rune(e.Source) {
+		if i >= e.Column-1 {
+			break
+		}
+		// keep tabs so the caret lines up however wide they are shown
+		if ch == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+	return e.Source + "\n" + caret.String() + "^"
+}
+
+// ParseErrors are all the mistakes found in a recipe, in the order of the lines they are on.
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// errorAt returns a ParseError for a mistake found in the statement being parsed, pointing at the last token that was
+// scanned.
+func (p *Parser) errorAt(st statement, err error) *ParseError {
+	e := &ParseError{
+		Line:    st.first + p.buf.line,
+		Column:  p.buf.col,
+		Source:  st.lines[p.buf.line],
+		Token:   p.buf.lit,
+		Message: err.Error(),
+	}
+	if p.buf.tok == EOF {
+		e.Token = ""
+	}
+	if p.buf.tok == ILLEGAL && strings.HasPrefix(p.buf.lit, `"`) {
+		// whatever was expected, the literal that was never closed is the mistake
+		e.Message = `unterminated literal, expected a " to close it`
+	}
+	return e
+}
+
+// errorOnStatement returns a ParseError for a mistake with a whole statement, pointing at the start of its first line.
+func errorOnStatement(st statement, err error) *ParseError {
+	source := st.lines[0]
+	column := len([]rune(source)) - len([]rune(strings.TrimLeft(source, " \t"))) + 1
+	return &ParseError{Line: st.first, Column: column, Source: source, Message: err.Error()}
+}
@@ -0,0 +1,117 @@
+package recipe
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse_Errors(t *testing.T) {
+	source := "# every line but the last has a mistake\n" +
+		"1 <- uppercase(1\n" +
+		"2 <- 2 -> shout\n" +
+		"\t3 = 3\n" +
+		"@lookup x\n" +
+		"4 <- \"a\" \"b\"\n" +
+		"$v <- sum\n" +
+		"5 <- 5\n"
+
+	_, err := Parse(strings.NewReader(source))
+	var errs ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Parse() error = %v, want ParseErrors", err)
+	}
+
+	want := ParseErrors{
+		{Line: 2, Column: 17, Source: "1 <- uppercase(1", Message: "expected , or ) in the arguments of uppercase, but found the end of the line"},
+		{Line: 3, Column: 11, Source: "2 <- 2 -> shout", Token: "shout", Message: "unrecognized function shout"},
+		{Line: 4, Column: 4, Source: "\t3 = 3", Token: "=", Message: "expected <- after 3, but found '='"},
+		{Line: 5, Column: 10, Source: "@lookup x", Message: "expected = but found EOF"},
+		{Line: 6, Column: 10, Source: "4 <- \"a\" \"b\"", Token: "b", Message: "expected ->, + or the end of the line after \"a\", but found \"b\""},
+		{Line: 7, Column: 1, Source: "$v <- sum", Message: "aggregate sum can only be used in a column recipe"},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("Parse() errors =\n%v\nwant\n%v", errs, want)
+	}
+	if !strings.HasPrefix(err.Error(), "error - line 2: expected , or )") {
+		t.Errorf("Error() = %q, want it to start with the first error", err.Error())
+	}
+}
+
+func TestParse_ErrorsOverSeveralLines(t *testing.T) {
+	source := "1 <- 1 ->\n" +
+		"  uppercase(1 +\n" +
+		"\n" +
+		"2 <- 2 ->\n" +
+		"  # shout it\n" +
+		"  shout\n"
+
+	_, err := Parse(strings.NewReader(source))
+	var errs ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Parse() error = %v, want ParseErrors", err)
+	}
+
+	// the unfinished statement ends where the next one starts
+	want := ParseErrors{
+		{Line: 2, Column: 16, Source: "  uppercase(1 +", Message: "expected a column, literal, variable, function or (, but found the end of the line"},
+		{Line: 6, Column: 3, Source: "  shout", Token: "shout", Message: "unrecognized function shout"},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("Parse() errors =\n%v\nwant\n%v", errs, want)
+	}
+}
+
+func TestParseError_Caret(t *testing.T) {
+	tests := []struct {
+		name string
+		err  ParseError
+		want string
+	}{
+		{
+			name: "caret under the column",
+			err:  ParseError{Line: 1, Column: 6, Source: "1 <- nope(2)"},
+			want: "1 <- nope(2)\n     ^",
+		},
+		{
+			name: "tabs are kept",
+			err:  ParseError{Line: 1, Column: 4, Source: "\t3 = 3"},
+			want: "\t3 = 3\n\t  ^",
+		},
+		{
+			name: "nothing without a line",
+			err:  ParseError{Message: "lookup x is not defined"},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Caret(); got != tt.want {
+				t.Errorf("Caret() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_UnterminatedLiteral(t *testing.T) {
+	source := "1 <- \"abc\n" +
+		"2 <- 2 -> lookup(\"x\", 1, @\"zip\n" +
+		"3 <- \"a\\\n"
+
+	_, err := Parse(strings.NewReader(source))
+	var errs ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Parse() error = %v, want ParseErrors", err)
+	}
+
+	message := "unterminated literal, expected a \" to close it"
+	want := ParseErrors{
+		{Line: 1, Column: 6, Source: "1 <- \"abc", Token: "\"abc", Message: message},
+		{Line: 2, Column: 26, Source: "2 <- 2 -> lookup(\"x\", 1, @\"zip", Token: "\"zip", Message: message},
+		{Line: 3, Column: 6, Source: "3 <- \"a\\", Token: "\"a", Message: message},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("Parse() errors =\n%v\nwant\n%v", errs, want)
+	}
+}
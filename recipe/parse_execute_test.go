@@ -641,6 +641,19 @@ func TestTransformation_ParseExecute(t *testing.T) {
 			input:  "2021-07-04,\n,\n2021-08-30,\n2021-08-31,\n2022-01-01,\n",
 			want:   "2021-07-04T00:00:00Z,\n,\n2021-08-30T00:00:00Z,\n2021-08-31T00:00:00Z,\n2022-01-01T00:00:00Z,\n",
 		},
+		{
+			name:   "normalize_date is an alias of readDate",
+			recipe: "1 <- 1 -> normalize_date(\"2006-01-02\") -> formatDate(\"Jan 2 2006\")",
+			input:  "2021-04-14\nbanana\n",
+			want:   "Apr 14 2021\nbanana\n",
+		},
+		{
+			name:        "fake with unknown kind is an error",
+			recipe:      "1 <- fake(\"unicorn\")",
+			input:       "a\n",
+			wantErr:     true,
+			wantErrText: "line 1 / column 1: fake(): unknown kind of fake data: 'unicorn'",
+		},
 		{
 			name:          "columns are automatically named if not provided in source",
 			recipe:        "1<-1\n2<-1\n3<-1\n",
@@ -662,6 +675,100 @@ func TestTransformation_ParseExecute(t *testing.T) {
 			processHeader: true,
 			want:          "header1,header2\na,b\nc,d\n",
 		},
+		{
+			name:          "filter removes rows that are not true",
+			recipe:        "1 <- 1\n2 <- 2\n? <- 2 -> eq(\"DEM\")\n",
+			input:         "name,party\nann,DEM\nbob,REP\ncat,DEM\ndan,\n",
+			processHeader: true,
+			want:          "name,party\nann,DEM\ncat,DEM\n",
+		},
+		{
+			name:   "every filter must be true to keep a row",
+			recipe: "1 <- 1\n? <- 2\n? <- 3 -> change(\"no\", \"false\")\n",
+			input:  "a,1,yes\nb,0,yes\nc,1,no\nd,,yes\ne,false,yes\nf,x,\n",
+			want:   "a\n",
+		},
+		{
+			name:        "filter errors report the filter number",
+			recipe:      "1 <- 1\n? <- 1\n? <- add(1, 2)\n",
+			input:       "1,2\n3,a\n",
+			wantErr:     true,
+			wantErrText: "line 2 / filter 2: add(): second arg to Add was not numeric: a",
+		},
+		{
+			name:          "explode writes a row for each part",
+			recipe:        "1 <- 1\n2 <- 2 -> explode(\";\")\n3 <- lineno + \".\" + subindex\n",
+			input:         "name,phone\nann,555-1234;555-9876\nbob,555-0000\ncat,\n",
+			processHeader: true,
+			want:          "name,phone,column 3\nann,555-1234,2.1\nann,555-9876,2.2\nbob,555-0000,3.1\ncat,,4.1\n",
+		},
+		{
+			name:   "explode more than one column uses the longest",
+			recipe: "1 <- 1 -> explode(\"|\")\n2 <- 2 -> explode(\"|\")\n",
+			input:  "a|b|c,1|2\n",
+			want:   "a,1\nb,2\nc,\n",
+		},
+		{
+			name:   "explode in a variable and filter the parts",
+			recipe: "$tag <- 2 -> explode(\",\") -> trim\n1 <- 1\n2 <- $tag\n? <- $tag -> change(\"skip\", \"\")\n",
+			input:  "a,\"red, skip, blue\"\n",
+			want:   "a,red\na,blue\n",
+		},
+		{
+			name:        "explode needs a separator",
+			recipe:      "1 <- 1 -> explode(\"\")\n",
+			input:       "a\n",
+			wantErr:     true,
+			wantErrText: "line 1 / column 1: explode(): separator cannot be empty",
+		},
+		{
+			name:          "reference columns by header name",
+			recipe:        "!1 <- \"zip\"\n1 <- @zipcode\n2 <- @\"first name\" -> uppercase\n3 <- add(@a_1, @\"b \\\"2\\\"\") -> numberFormat(\"0\")\n",
+			input:         "\ufefffirst name,a_1, zipcode ,\"b \"\"2\"\"\"\nann,1,80202,2\nbob,3,80301,4\n",
+			processHeader: true,
+			want:          "zip,a_1,\" zipcode \"\n80202,ANN,3\n80301,BOB,7\n",
+		},
+		{
+			name:          "names must be in the header",
+			recipe:        "1 <- @zipcode\n",
+			input:         "zip\n80202\n",
+			processHeader: true,
+			wantErr:       true,
+			wantErrText:   "column @zipcode not found in the header",
+		},
+		{
+			name:          "names must be in the header only once",
+			recipe:        "1 <- @\"zip code\"\n",
+			input:         "zip code,zip code\n80202,80301\n",
+			processHeader: true,
+			wantErr:       true,
+			wantErrText:   "column @\"zip code\" is in the header more than once",
+		},
+		{
+			name:        "names need the header",
+			recipe:      "1 <- 1\n2 <- 2 -> lookup(\"x\", 1, @zip)\n@lookup x = \"x.csv\" key 1\n",
+			input:       "80202\n",
+			wantErr:     true,
+			wantErrText: "columns can only be referenced by name, like @zip, when the header is processed",
+		},
+		{
+			name:   "when picks a value from a condition",
+			recipe: "$adult <- 2 -> ge(\"18\")\n1 <- 1\n2 <- when($adult, \"adult\", \"minor\")\n3 <- 2 -> gt(\"64\") -> when(?, \"senior\", \"\")\n",
+			input:  "ann,17\nbob,18\ncat,70\n",
+			want:   "ann,minor,\nbob,adult,\ncat,adult,senior\n",
+		},
+		{
+			name:   "combine conditions",
+			recipe: "$co <- 2 -> eq(\"CO\")\n1 <- 1\n2 <- 3 -> startsWith(\"80\") -> and($co) -> not -> when(?, \"check\", \"ok\")\n",
+			input:  "ann,CO,80202\nbob,UT,84601\ncat,CO,84601\n",
+			want:   "ann,ok\nbob,check\ncat,check\n",
+		},
+		{
+			name:   "case with several branches and a default",
+			recipe: "1 <- 1\n2 <- 2 -> case(\"DEM\", \"Democrat\", \"REP\", \"Republican\", \"Other\")\n3 <- 2 -> switch(\"DEM\", \"D\", ?)\n",
+			input:  "ann,DEM\nbob,REP\ncat,GRN\n",
+			want:   "ann,Democrat,D\nbob,Republican,REP\ncat,Other,GRN\n",
+		},
 		{
 			name:             "column can only be defined once",
 			recipe:           "1 <- 1\n1<-1\n",
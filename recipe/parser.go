@@ -5,48 +5,100 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
 
-var allFuncs = map[string][]int{
-	"uppercase":    {1},
-	"lowercase":    {1},
-	"join":         {1},
-	"add":          {2},
-	"subtract":     {2},
-	"multiply":     {2},
-	"divide":       {2},
-	"change":       {3},
-	"changei":      {3},
-	"ifempty":      {3},
-	"isempty":      {3}, // alias for ifempty
-	"numberformat": {2},
-	"lineno":       {0},
-	"removedigits": {1},
-	"onlydigits":   {1},
-	"mod":          {2},
-	"trim":         {1},
-	"firstchars":   {1},
-	"lastchars":    {1},
-	"repeat":       {2},
-	"replace":      {3},
-	"today":        {0},
-	"now":          {0},
-	"formatdate":   {2},
-	"formatdatef":  {2},
-	"readdate":     {2},
-	"readdatef":    {2},
-	"smartdate":    {1},
-	"ispast":       {3},
-	"isfuture":     {3},
-
-	"normalize_date": {1, 1},
-	"fake":           {1},
+// Parse reads a recipe using the functions in the DefaultRegistry.
+func Parse(source io.Reader) (*Transformation, error) {
+	return ParseWithRegistry(source, nil)
 }
 
-func Parse(source io.Reader) (*Transformation, error) {
+// ParseOptions controls how a recipe is read.
+type ParseOptions struct {
+	Registry *FunctionRegistry // functions available to the recipe, nil uses the DefaultRegistry
+	Params   map[string]string // values of the recipe's parameters by name, ex: batch for $param.batch
+}
+
+// ParseFile reads a recipe from a file using the functions in the DefaultRegistry. Files named in the recipe, like
+// lookup tables and included recipes, are found relative to the recipe file.
+func ParseFile(path string) (*Transformation, error) {
+	return ParseFileWithOptions(path, ParseOptions{})
+}
+
+// ParseFileWithOptions reads a recipe from a file like ParseFile, with the given functions and parameters.
+func ParseFileWithOptions(path string, options ParseOptions) (*Transformation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	transformation, err := parseRecipe(f, path, options)
+	if err != nil {
+		return nil, err
+	}
+	transformation.Dir = filepath.Dir(path)
+	return transformation, nil
+}
+
+// ParseWithRegistry reads a recipe using the functions in the given registry. The registry is kept with the
+// Transformation so the same functions are used when it is executed. A nil registry uses the DefaultRegistry.
+// Included recipes are found relative to the working directory.
+func ParseWithRegistry(source io.Reader, registry *FunctionRegistry) (*Transformation, error) {
+	return parseRecipe(source, "", ParseOptions{Registry: registry})
+}
+
+// ParseWithOptions reads a recipe with the given functions and parameters.
+func ParseWithOptions(source io.Reader, options ParseOptions) (*Transformation, error) {
+	return parseRecipe(source, "", options)
+}
+
+// parseRecipe reads a recipe, and the recipes it includes. The path is the file the recipe was read from, if any.
+func parseRecipe(source io.Reader, path string, options ParseOptions) (*Transformation, error) {
 	transformation := NewTransformation()
+	transformation.Functions = options.Registry
+	for name, value := range options.Params {
+		transformation.setParam(name, value)
+	}
+
+	rp := &recipeParser{t: transformation}
+	dir := ""
+	if path != "" {
+		if err := rp.enter(path); err != nil {
+			return nil, err
+		}
+		dir = filepath.Dir(path)
+	}
+	if errs := rp.parse(source, "", dir); len(errs) > 0 {
+		return nil, errs
+	}
+
+	if err := transformation.validateLookups(); err != nil {
+		return nil, ParseErrors{{Message: err.Error()}}
+	}
+	if err := transformation.validateParams(); err != nil {
+		return nil, ParseErrors{{Message: err.Error()}}
+	}
+	if err := transformation.validateTypes(); err != nil {
+		return nil, ParseErrors{{Message: err.Error()}}
+	}
+
+	return transformation, nil
+}
+
+// recipeParser reads the statements of a recipe, and of the recipes it includes, into a Transformation.
+type recipeParser struct {
+	t         *Transformation
+	including []includedFile // files being parsed, each included by the one before it
+}
+
+// parse reads the statements of a recipe. The file is the name of the included recipe being read, empty for the
+// recipe itself, and the dir is where the files it includes are found.
+func (rp *recipeParser) parse(source io.Reader, file string, dir string) ParseErrors {
+	transformation := rp.t
 
 	// split by newlines
 	buf := new(bytes.Buffer)
@@ -54,136 +106,214 @@ func Parse(source io.Reader) (*Transformation, error) {
 	s := buf.String()
 	lines := strings.Split(s, "\n")
 
-	for lineNo, l := range lines {
-		if strings.TrimSpace(l) == "" {
-			// blank lines make the reader get a \0 which is eof which causes the parser to exit, so we
-			// ignore that right here.
-			continue
-		}
-		p := NewParser(strings.NewReader(l))
+	var errs ParseErrors
+	fail := func(e *ParseError) {
+		e.File = file
+		errs = append(errs, e)
+	}
+	for _, st := range splitStatements(lines) {
+		p := NewParser(strings.NewReader(strings.Join(st.lines, "\n")))
 
-		// Full Line Comment
 		tok, lit := p.scanIgnoreWhitespace()
-		if tok == COMMENT {
-			p.scanComment()
-			continue
-		}
 		if tok == EOF {
-			break
-		}
-
-		if tok != COLUMN_ID && tok != VARIABLE && tok != HEADER {
-			return transformation, fmt.Errorf("expected column, header or variable on line %d, but found %s", lineNo, lit)
+			// nothing but a comment
+			continue
 		}
-
-		// Found column or variable to assign result to
-		target := lit
-		var targetType DataType
-		if tok == COLUMN_ID {
-			err := transformation.AddOutputToColumn(lit)
-			if err != nil {
-				return nil, fmt.Errorf("error - line %d: %s", lineNo+1, err.Error())
-			}
-			targetType = Column
-		} else if tok == VARIABLE {
-			err := transformation.AddOutputToVariable(lit)
+		if isInclude(tok, lit) {
+			included, err := rp.include(p, dir)
 			if err != nil {
-				return nil, fmt.Errorf("error - line %d: %s", lineNo+1, err.Error())
+				fail(p.errorAt(st, err))
 			}
-			transformation.VariableOrder = append(transformation.VariableOrder, lit)
-			targetType = Variable
-		} else if tok == HEADER {
-			err := transformation.AddOutputToHeader(lit)
-			if err != nil {
-				return nil, fmt.Errorf("error - line %d: %s", lineNo+1, err.Error())
+			errs = append(errs, included...)
+			continue
+		}
+		if tok == DIRECTIVE {
+			if err := parseDirective(p, transformation, lit); err != nil {
+				fail(p.errorAt(st, err))
 			}
-			targetType = Header
+			continue
 		}
 
-		// After column or variable, we need the assignment <- operator
-		if err := consumeAssignment(p); err != nil {
-			return nil, err
+		targetType, target, err := parseAssignment(p, transformation, tok, lit)
+		if err != nil {
+			// keep going with the next statement so every mistake in the recipe is found at once
+			fail(p.errorAt(st, err))
+			continue
 		}
+		if err := transformation.validateAggregates(transformation.getRecipeByType(targetType, target)); err != nil {
+			fail(errorOnStatement(st, err))
+		}
+	}
+	return errs
+}
 
-		// grab first pipe piece - literal, column, variable, function, function w/ args
-		tok, lit = p.scanIgnoreWhitespace()
-		switch tok {
-		case COLUMN_ID:
-			transformation.AddOperationByType(targetType, target, getColumn(lit))
-		case LITERAL:
-			transformation.AddOperationByType(targetType, target, getLiteral(lit))
-		case VARIABLE:
-			transformation.AddOperationByType(targetType, target, getVariable(lit))
-		case FUNCTION:
-			function := lit
-			operation, err := consumeFunctionArgs(p, function)
-			if err != nil {
-				return nil, err
+// statement is one or more lines of a recipe that are parsed together.
+type statement struct {
+	first int      // line number of the first line, starting at 1
+	lines []string // the lines of the statement
+}
+
+// splitStatements groups the lines of a recipe into statements. A line that ends with -> or +, or leaves a parenthesis
+// open, carries on to the next line. Blank lines and comments can come between the lines of a statement.
+func splitStatements(lines []string) []statement {
+	var statements []statement
+	var current *statement
+	depth := 0           // parentheses left open
+	var last Token       // last token of the statement that isn't whitespace or a comment
+	var pending []string // blank lines and comments, only part of the statement if it carries on after them
+	for i, l := range lines {
+		tokens, first := lineTokens(l)
+		if current != nil && startsStatement(tokens, first) {
+			// the last statement was never finished, it ends here so the mistake is found on the right line
+			current = nil
+		}
+		if len(tokens) == 0 {
+			// blank or only a comment
+			if current != nil {
+				pending = append(pending, l)
 			}
-			transformation.AddOperationByType(targetType, target, operation)
-		default:
-			return nil, fmt.Errorf("unexpected token [%d] %s\n", tok, lit)
+			continue
 		}
-
-	LOOPSCAN:
-		for {
-			tok, lit := p.scanIgnoreWhitespace()
-			switch tok {
-			case EOF:
-				break LOOPSCAN
-			case PIPE:
-				break
-			case PLUS:
-				transformation.AddOperationByType(targetType, target, getJoinWithPlaceholder())
-			case COMMENT:
-				if targetType == Variable {
-					recipe := transformation.Variables[target]
-					recipe.Comment = lit
-					transformation.Variables[target] = recipe
-				}
-				if targetType == Column {
-					columnNum, _ := strconv.Atoi(target)
-					recipe := transformation.Columns[columnNum]
-					recipe.Comment = lit
-					transformation.Columns[columnNum] = recipe
-				}
-				if targetType == Header {
-					headerNum, _ := strconv.Atoi(target)
-					recipe := transformation.Headers[headerNum]
-					recipe.Comment = lit
-					transformation.Headers[headerNum] = recipe
-				}
-				break LOOPSCAN
-			default:
-				break
+		if current == nil {
+			pending = nil
+			statements = append(statements, statement{first: i + 1, lines: []string{l}})
+			if tokens[0] == DIRECTIVE {
+				// directives are always a single line
+				continue
 			}
+			current = &statements[len(statements)-1]
+			depth = 0
+		} else {
+			current.lines = append(append(current.lines, pending...), l)
+			pending = nil
+		}
 
-			// After connection scan stuff we can do (column, variable, literal, function)
-			// Comments or EOL are no bueno here like 1 <- 2 + # comment <- what??
-			tok, lit = p.scanIgnoreWhitespace()
+		for _, tok := range tokens {
 			switch tok {
-			case COLUMN_ID:
-				transformation.AddOperationByType(targetType, target, getColumn(lit))
-			case VARIABLE:
-				transformation.AddOperationByType(targetType, target, getVariable(lit))
-			case LITERAL:
-				transformation.AddOperationByType(targetType, target, getLiteral(lit))
-			case FUNCTION:
-				function := lit
-				operation, err := consumeFunctionArgs(p, function)
-				if err != nil {
-					return nil, err
-				}
-				transformation.AddOperationByType(targetType, target, operation)
-			case PLACEHOLDER:
-				transformation.AddOperationByType(targetType, target, getPlaceholder())
-			default:
-				return nil, fmt.Errorf("unexpected token [%d]-'%s' in parse loop", tok, lit)
+			case OPEN_PAREN:
+				depth++
+			case CLOSE_PAREN:
+				depth--
 			}
+			last = tok
+		}
+		if depth <= 0 && last != PIPE && last != PLUS {
+			current = nil
 		}
 	}
+	return statements
+}
 
-	return transformation, nil
+// startsStatement reports whether the tokens of a line start a statement of their own, which can't carry on from the
+// line above. The first token is given to tell a directive from a named column.
+func startsStatement(tokens []Token, first string) bool {
+	if len(tokens) > 0 && tokens[0] == DIRECTIVE {
+		name := strings.ToLower(strings.TrimPrefix(first, "@"))
+		_, ok := directiveParsers[name]
+		return ok || name == "include"
+	}
+	if len(tokens) < 2 || tokens[1] != ASSIGNMENT {
+		return false
+	}
+	switch tokens[0] {
+	case COLUMN_ID, VARIABLE, HEADER, PLACEHOLDER:
+		return true
+	}
+	return false
+}
+
+// lineTokens returns the tokens of a line that aren't whitespace or comments, and the text of the first one.
+func lineTokens(l string) ([]Token, string) {
+	var tokens []Token
+	var first string
+	p := NewParser(strings.NewReader(l))
+	for tok, lit := p.scanIgnoreWhitespace(); tok != EOF; tok, lit = p.scanIgnoreWhitespace() {
+		if tokens == nil {
+			first = lit
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens, first
+}
+
+// parseAssignment parses a statement that assigns an expression to a column, header, variable or filter, starting from
+// the target that was scanned. It returns the type of the target and the target the recipe was added to.
+func parseAssignment(p *Parser, transformation *Transformation, tok Token, lit string) (DataType, string, error) {
+	if tok != COLUMN_ID && tok != VARIABLE && tok != HEADER && tok != PLACEHOLDER {
+		return 0, "", fmt.Errorf("expected a column, header, variable or filter, but found %s", describeToken(tok, lit))
+	}
+
+	// Found column or variable to assign result to
+	target := lit
+	var targetType DataType
+	if tok == COLUMN_ID {
+		if err := transformation.AddOutputToColumn(lit); err != nil {
+			return 0, "", err
+		}
+		targetType = Column
+	} else if tok == VARIABLE {
+		if isParam(lit) {
+			return 0, "", fmt.Errorf("%s is a parameter, it can't be assigned", lit)
+		}
+		if err := transformation.AddOutputToVariable(lit); err != nil {
+			return 0, "", err
+		}
+		transformation.VariableOrder = append(transformation.VariableOrder, lit)
+		targetType = Variable
+	} else if tok == HEADER {
+		if err := transformation.AddOutputToHeader(lit); err != nil {
+			return 0, "", err
+		}
+		targetType = Header
+	} else if tok == PLACEHOLDER {
+		target = transformation.AddFilter()
+		targetType = Filter
+	}
+
+	// After column or variable, we need the assignment <- operator
+	if err := consumeAssignment(p, lit); err != nil {
+		return 0, "", err
+	}
+
+	// the rest of the line is an expression, optionally followed by a comment
+	parser := expressionParser{p: p, registry: transformation.registry()}
+	expr, err := parser.parseExpression()
+	if err != nil {
+		return 0, "", err
+	}
+	for _, operation := range expr.compile() {
+		transformation.AddOperationByType(targetType, target, operation)
+	}
+
+	if tok, lit := p.scanIgnoreWhitespace(); tok != EOF {
+		return 0, "", fmt.Errorf("expected ->, + or the end of the line after %s, but found %s", expr, describeToken(tok, lit))
+	}
+
+	// the comments between the pieces of a statement are kept together
+	if lit := strings.Join(p.comments, "\n"); lit != "" {
+		if targetType == Variable {
+			recipe := transformation.Variables[target]
+			recipe.Comment = lit
+			transformation.Variables[target] = recipe
+		}
+		if targetType == Column {
+			columnNum, _ := strconv.Atoi(target)
+			recipe := transformation.Columns[columnNum]
+			recipe.Comment = lit
+			transformation.Columns[columnNum] = recipe
+		}
+		if targetType == Header {
+			headerNum, _ := strconv.Atoi(target)
+			recipe := transformation.Headers[headerNum]
+			recipe.Comment = lit
+			transformation.Headers[headerNum] = recipe
+		}
+		if targetType == Filter {
+			filterNum, _ := strconv.Atoi(target)
+			transformation.Filters[filterNum-1].Comment = lit
+		}
+	}
+	return targetType, target, nil
 }
 
 func getLiteral(lit string) Operation {
@@ -221,6 +351,15 @@ func getFunction(name string, args []Argument) Operation {
 	}
 }
 
+func getNamedColumn(name string) Operation {
+	return Operation{
+		Name: "value",
+		Arguments: []Argument{
+			namedColumnArg(name),
+		},
+	}
+}
+
 func getVariable(lit string) Operation {
 	return Operation{
 		Name: "value",
@@ -253,6 +392,13 @@ func getOutputForVariable(v string) Output {
 	}
 }
 
+func getOutputForFilter(f string) Output {
+	return Output{
+		Type:  Filter,
+		Value: f,
+	}
+}
+
 func getOutputForHeader(h string) Output {
 	return Output{
 		Type:  Header,
@@ -260,80 +406,14 @@ func getOutputForHeader(h string) Output {
 	}
 }
 
-func consumeAssignment(p *Parser) error {
+func consumeAssignment(p *Parser, target string) error {
 	tok, lit := p.scanIgnoreWhitespace()
 	if tok != ASSIGNMENT {
-		return fmt.Errorf("expected assignment ( <- ) but found [%s] (%d) instead.\n", lit, tok)
+		return fmt.Errorf("expected <- after %s, but found %s", target, describeToken(tok, lit))
 	}
 	return nil
 }
 
-func consumeFunctionArgs(p *Parser, name string) (Operation, error) {
-	// check if the function even exists
-	funcArgs, ok := allFuncs[strings.ToLower(name)]
-	if !ok {
-		return Operation{}, fmt.Errorf("unrecognized function %s", name)
-	}
-	var totalArgs int
-
-	for _, count := range funcArgs {
-		totalArgs += count
-	}
-
-	// look for paren
-	tok, _ := p.scan()
-
-	var operation Operation
-
-	operation.Name = name
-	// if not a paren, then we have the default placeholder arg
-	if tok != OPEN_PAREN {
-		// Whatever it was, we can let it get parsed elsewhere
-		p.s.unread()
-		operation.Arguments = []Argument{}
-		for i := 0; i < totalArgs; i++ {
-			operation.Arguments = append(operation.Arguments, placeholderArg())
-		}
-
-		return operation, nil
-	}
-
-	var gotPlaceholder bool // track if the placeholder was explicitly provided or not
-	var args []Argument
-ARGLOOP:
-	for {
-		tok, lit := p.scanIgnoreWhitespace()
-		switch tok {
-		case EOF:
-			return operation, fmt.Errorf("expected function args for %s. found EOF", name)
-		case LITERAL:
-			args = append(args, literalArg(lit))
-		case PLACEHOLDER:
-			gotPlaceholder = true
-			args = append(args, placeholderArg())
-		case COLUMN_ID:
-			args = append(args, columnArg(lit))
-		case VARIABLE:
-			args = append(args, variableArg(lit))
-		case COMMA:
-			break
-		case CLOSE_PAREN:
-			break ARGLOOP
-		default:
-			return operation, fmt.Errorf("expected function args, got [%d] - %s", tok, lit)
-		}
-	}
-
-	if !gotPlaceholder || len(args) == 0 {
-		args = append(args, placeholderArg())
-	}
-	//var args []Argument
-	// must now get args until we get a close paren
-	operation.Arguments = args
-
-	return operation, nil
-}
-
 func variableArg(lit string) Argument {
 	return Argument{
 		Type:  Variable,
@@ -348,6 +428,21 @@ func columnArg(lit string) Argument {
 	}
 }
 
+func namedColumnArg(name string) Argument {
+	return Argument{
+		Type:  NamedColumn,
+		Value: name,
+	}
+}
+
+// columnName returns the header name from a named column reference, which only includes the @ if it was not quoted.
+func columnName(tok Token, lit string) string {
+	if tok == DIRECTIVE {
+		return strings.TrimPrefix(lit, "@")
+	}
+	return lit
+}
+
 func literalArg(lit string) Argument {
 	return Argument{
 		Type:  Literal,
@@ -382,7 +477,12 @@ func isDigit(ch rune) bool {
 var eof = rune(0)
 
 type Scanner struct {
-	r *bufio.Reader
+	r    *bufio.Reader
+	line int // lines read so far
+	col  int // runes read so far on the current line
+	last struct {
+		line, col int
+	} // position before the last rune read, to go back to when it is unread
 }
 
 func NewScanner(r io.Reader) *Scanner {
@@ -392,10 +492,13 @@ func NewScanner(r io.Reader) *Scanner {
 type Parser struct {
 	s   *Scanner
 	buf struct {
-		tok Token
-		lit string
-		n   int
+		tok  Token
+		lit  string
+		line int // line of the token, starting at 0
+		col  int // column of the token, starting at 1
+		n    int
 	}
+	comments []string // comments that were skipped over, in the order they were found
 }
 
 // read reads the next rune from the buffered reader
@@ -405,10 +508,21 @@ func (s *Scanner) read() rune {
 	if err != nil {
 		return eof
 	}
+	s.last.line, s.last.col = s.line, s.col
+	if ch == '\n' {
+		s.line++
+		s.col = 0
+	} else {
+		s.col++
+	}
 	return ch
 }
 
-func (s *Scanner) unread() { _ = s.r.UnreadRune() }
+func (s *Scanner) unread() {
+	if s.r.UnreadRune() == nil {
+		s.line, s.col = s.last.line, s.last.col
+	}
+}
 
 // Scan returns the next token and literal value
 func (s *Scanner) Scan() (tok Token, lit string) {
@@ -434,6 +548,9 @@ func (s *Scanner) Scan() (tok Token, lit string) {
 	} else if ch == '$' {
 		s.unread()
 		return s.scanVariable()
+	} else if ch == '@' {
+		s.unread()
+		return s.scanDirective()
 	} else if ch == '"' {
 		s.unread()
 		return s.scanLiteral()
@@ -460,6 +577,8 @@ func (s *Scanner) Scan() (tok Token, lit string) {
 		return CLOSE_PAREN, string(ch)
 	case ',':
 		return COMMA, string(ch)
+	case '=':
+		return EQUALS, string(ch)
 	}
 
 	return ILLEGAL, string(ch)
@@ -556,6 +675,8 @@ func (s *Scanner) scanColumn() (Token, string) {
 	return COLUMN_ID, buf.String()
 }
 
+// scanLiteral scans a quoted literal. A literal that isn't closed before the end of the line is ILLEGAL, its text
+// starts with the quote so it can be told apart from other mistakes.
 func (s *Scanner) scanLiteral() (Token, string) {
 	// Create a buffer and read the current character into it.
 	var buf bytes.Buffer
@@ -565,12 +686,15 @@ func (s *Scanner) scanLiteral() (Token, string) {
 	for {
 		ch := s.read()
 		if ch == '\\' {
-			_, _ = buf.WriteRune(s.read())
-		} else if ch != '"' {
-			_, _ = buf.WriteRune(ch)
-		} else {
+			ch = s.read()
+		} else if ch == '"' {
 			break
 		}
+		if ch == eof || ch == '\n' {
+			s.unread()
+			return ILLEGAL, `"` + buf.String()
+		}
+		_, _ = buf.WriteRune(ch)
 	}
 
 	return LITERAL, buf.String()
@@ -588,6 +712,12 @@ func (s *Scanner) scanVariable() (Token, string) {
 			break
 		} else if isLetter(ch) {
 			_, _ = buf.WriteRune(ch)
+		} else if ch == '.' && buf.String()+"." == paramPrefix {
+			// the only dot is the one after $param, ex: $param.batch
+			_, _ = buf.WriteRune(ch)
+		} else if (isDigit(ch) || ch == '_') && isParam(buf.String()) && buf.Len() > len(paramPrefix) {
+			// after its first letter a parameter name can have digits and underscores, ex: $param.batch_2
+			_, _ = buf.WriteRune(ch)
 		} else {
 			s.unread()
 			break
@@ -597,6 +727,36 @@ func (s *Scanner) scanVariable() (Token, string) {
 	return VARIABLE, buf.String()
 }
 
+// scanDirective scans an @ followed by a name. This is either a directive, at the start of a line, or a reference to
+// an input column by its header name. Header names that are not simple words are quoted, ex: @"first name"
+func (s *Scanner) scanDirective() (Token, string) {
+	// Create a buffer and read the current character into it.
+	var buf bytes.Buffer
+	buf.WriteRune(s.read())
+
+	ch := s.read()
+	s.unread()
+	if ch == '"' {
+		tok, lit := s.scanLiteral()
+		if tok == ILLEGAL {
+			return tok, lit
+		}
+		return COLUMN_NAME, lit
+	}
+
+	for {
+		ch := s.read()
+		if isLetter(ch) || isDigit(ch) || ch == '_' {
+			_, _ = buf.WriteRune(ch)
+		} else {
+			s.unread()
+			break
+		}
+	}
+
+	return DIRECTIVE, buf.String()
+}
+
 func (s *Scanner) scanComment() (Token, string) {
 	// Create a buffer and read the current character into it.
 	var buf bytes.Buffer
@@ -613,14 +773,18 @@ func (s *Scanner) scanComment() (Token, string) {
 	return COMMENT, strings.TrimSpace(buf.String())
 }
 
-// scanIgnoreWhitespace scans the next non-whitespace token.
+// scanIgnoreWhitespace scans the next token that isn't whitespace or a comment. Comments are kept in the parser.
 func (p *Parser) scanIgnoreWhitespace() (tok Token, lit string) {
-	tok, lit = p.scan()
-	if tok == WS || tok == NEWLINE {
+	for {
 		tok, lit = p.scan()
+		switch tok {
+		case WS, NEWLINE:
+		case COMMENT:
+			p.comments = append(p.comments, lit)
+		default:
+			return
+		}
 	}
-
-	return
 }
 
 // scan returns the next token from the underlying scanner.
@@ -633,26 +797,14 @@ func (p *Parser) scan() (tok Token, lit string) {
 	}
 
 	// Otherwise read the next token from the scanner.
+	line, col := p.s.line, p.s.col+1
 	tok, lit = p.s.Scan()
 
 	// Save it to the buffer in case we unscan it later.
-	p.buf.tok, p.buf.lit = tok, lit
+	p.buf.tok, p.buf.lit, p.buf.line, p.buf.col = tok, lit, line, col
 
 	return
 }
 
-func (p *Parser) scanComment() string {
-	var tok Token
-	var lit string
-	var comment strings.Builder
-
-	for {
-		tok, lit = p.scan()
-		if tok == EOF || tok == NEWLINE {
-			break
-		}
-		comment.WriteString(lit)
-	}
-
-	return strings.TrimSpace(comment.String())
-}
+// unscan pushes the previously read token back onto the buffer.
+func (p *Parser) unscan() { p.buf.n = 1 }
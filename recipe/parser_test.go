@@ -539,6 +539,149 @@ func TestParse(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "filter with comment",
+			args: args{source: strings.NewReader("? <- 9 -> eq(\"DEM\") # only democrats")},
+			want: &Transformation{
+				Variables: map[string]Recipe{},
+				Columns:   map[int]Recipe{},
+				Headers:   map[int]Recipe{},
+				Filters: []Recipe{
+					{
+						Output: getOutputForFilter("1"),
+						Pipe: []Operation{
+							getColumn("9"),
+							getFunction("eq", []Argument{
+								literalArg("DEM"),
+								placeholderArg(),
+							}),
+						},
+						Comment: "only democrats",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "more than one filter",
+			args: args{source: strings.NewReader("? <- 1\n? <- $keep\n")},
+			want: &Transformation{
+				Variables: map[string]Recipe{},
+				Columns:   map[int]Recipe{},
+				Headers:   map[int]Recipe{},
+				Filters: []Recipe{
+					{
+						Output: getOutputForFilter("1"),
+						Pipe:   []Operation{getColumn("1")},
+					},
+					{
+						Output: getOutputForFilter("2"),
+						Pipe:   []Operation{getVariable("$keep")},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "nested function call",
+			args: args{source: strings.NewReader("1 <- uppercase(firstChars(\"1\", 2))")},
+			want: &Transformation{
+				Variables: map[string]Recipe{},
+				Columns: map[int]Recipe{
+					1: {
+						Output: getOutputForColumn("1"),
+						Pipe: []Operation{
+							getFunction("uppercase", []Argument{
+								{
+									Type:  Expression,
+									Value: "firstChars(\"1\", 2)",
+									Pipe: []Operation{
+										getFunction("firstChars", []Argument{
+											literalArg("1"),
+											columnArg("2"),
+											placeholderArg(),
+										}),
+									},
+								},
+								placeholderArg(),
+							}),
+						},
+					},
+				},
+				Headers: map[int]Recipe{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "grouped expression",
+			args: args{source: strings.NewReader("1 <- 1 + (2 -> lowercase)")},
+			want: &Transformation{
+				Variables: map[string]Recipe{},
+				Columns: map[int]Recipe{
+					1: {
+						Output: getOutputForColumn("1"),
+						Pipe: []Operation{
+							getColumn("1"),
+							getJoinWithPlaceholder(),
+							{
+								Name: "value",
+								Arguments: []Argument{
+									{
+										Type:  Expression,
+										Value: "(2 -> lowercase)",
+										Pipe: []Operation{
+											getColumn("2"),
+											getFunction("lowercase", []Argument{placeholderArg()}),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Headers: map[int]Recipe{},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "error when a group is not closed",
+			args:    args{source: strings.NewReader("1 <- (1 + 2")},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "error when an aggregate is nested",
+			args:    args{source: strings.NewReader("1 <- uppercase(sum(1))")},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "statement over several lines with comments",
+			args: args{source: strings.NewReader("# not part of it\n1 <- 1 -> # first\n  uppercase(\n    # second\n    2\n  ) +\n\n  3 # third\n2 <- 2\n")},
+			want: &Transformation{
+				Variables: map[string]Recipe{},
+				Columns: map[int]Recipe{
+					1: {
+						Output: getOutputForColumn("1"),
+						Pipe: []Operation{
+							getColumn("1"),
+							getFunction("uppercase", []Argument{columnArg("2"), placeholderArg()}),
+							getJoinWithPlaceholder(),
+							getColumn("3"),
+						},
+						Comment: "first\nsecond\nthird",
+					},
+					2: {
+						Output: getOutputForColumn("2"),
+						Pipe: []Operation{
+							getColumn("2"),
+						},
+					},
+				},
+				Headers: map[int]Recipe{},
+			},
+			wantErr: false,
+		},
 		{
 			name:    "columns can only be defined once",
 			args:    args{source: strings.NewReader("1 <- 1\n1<-1\n")},
@@ -0,0 +1,62 @@
+package recipe
+
+// defaultProgressEvery is how many rows are read between progress reports when ProgressEvery is not set.
+const defaultProgressEvery = 1000
+
+// Progress is a snapshot of how far an execution has gotten.
+type Progress struct {
+	RowsRead    int   // rows read from the input, including the header
+	RowsWritten int   // rows written to the output, including the header
+	BytesRead   int64 // bytes of input consumed, or -1 if the reader cannot tell
+}
+
+// progressReporter calls the Progress callback every so many rows. It is only used from the goroutine that writes
+// the output, so it needs no locking.
+type progressReporter struct {
+	callback func(Progress)
+	every    int
+	last     int
+}
+
+func newProgressReporter(options ExecuteOptions) *progressReporter {
+	every := options.ProgressEvery
+	if every <= 0 {
+		every = defaultProgressEvery
+	}
+	return &progressReporter{callback: options.Progress, every: every}
+}
+
+// update reports progress if enough rows have been read since the last report.
+func (p *progressReporter) update(progress Progress) {
+	if p.callback == nil || progress.RowsRead-p.last < p.every {
+		return
+	}
+	p.report(progress)
+}
+
+// report calls the Progress callback unconditionally.
+func (p *progressReporter) report(progress Progress) {
+	if p.callback == nil {
+		return
+	}
+	p.last = progress.RowsRead
+	p.callback(progress)
+}
+
+// inputOffset returns how many bytes the reader has consumed, when the reader is able to say, or -1. The offset
+// changes with every Read, so it must be called from the goroutine that is reading.
+func inputOffset(reader RowReader) int64 {
+	if r, ok := reader.(interface{ InputOffset() int64 }); ok {
+		return r.InputOffset()
+	}
+	return -1
+}
+
+// inputFilename returns the path of the file the reader is reading, when the reader is able to say, or "". Like the
+// offset, it must be called from the goroutine that is reading.
+func inputFilename(reader RowReader) string {
+	if r, ok := reader.(interface{ Filename() string }); ok {
+		return r.Filename()
+	}
+	return ""
+}
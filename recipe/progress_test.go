@@ -0,0 +1,111 @@
+package recipe
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTransformation_ExecuteContextProgress(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("id,name\n")
+	for i := 1; i <= 25; i++ {
+		_, _ = fmt.Fprintf(&input, "%d,name %d\n", i, i)
+	}
+
+	transformation, err := Parse(strings.NewReader("1 <- 1\n2 <- 2\n? <- mod(1, \"5\")\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+
+	for _, workers := range []int{1, 4} {
+		var reports []Progress
+		options := ExecuteOptions{
+			Workers:       workers,
+			ProgressEvery: 10,
+			Progress:      func(p Progress) { reports = append(reports, p) },
+		}
+		var b bytes.Buffer
+		_, err := transformation.ExecuteContext(context.Background(), csv.NewReader(strings.NewReader(input.String())), csv.NewWriter(&b), true, -1, options)
+		if err != nil {
+			t.Fatalf("workers %d: execute error = %v", workers, err)
+		}
+
+		want := []Progress{
+			{RowsRead: 10, RowsWritten: 9},
+			{RowsRead: 20, RowsWritten: 17},
+			{RowsRead: 26, RowsWritten: 21},
+		}
+		if len(reports) != len(want) {
+			t.Fatalf("workers %d: got %d progress reports, want %d: %+v", workers, len(reports), len(want), reports)
+		}
+		for i, got := range reports {
+			if got.RowsRead != want[i].RowsRead || got.RowsWritten != want[i].RowsWritten {
+				t.Errorf("workers %d: report %d = %+v, want %+v", workers, i, got, want[i])
+			}
+			if i > 0 && got.BytesRead >= 0 && got.BytesRead < reports[i-1].BytesRead {
+				t.Errorf("workers %d: bytes read went backwards: %+v", workers, reports)
+			}
+		}
+		if last := reports[len(reports)-1]; last.BytesRead >= 0 && last.BytesRead != int64(input.Len()) {
+			t.Errorf("workers %d: final bytes read = %d, want %d", workers, last.BytesRead, input.Len())
+		}
+	}
+}
+
+func TestTransformation_ExecuteContextCancel(t *testing.T) {
+	var input strings.Builder
+	for i := 1; i <= 5000; i++ {
+		_, _ = fmt.Fprintf(&input, "%d\n", i)
+	}
+
+	transformation, err := Parse(strings.NewReader("1 <- 1\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+
+	t.Run("cancelled before starting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var b bytes.Buffer
+		_, err := transformation.ExecuteContext(ctx, csv.NewReader(strings.NewReader(input.String())), csv.NewWriter(&b), true, -1, ExecuteOptions{})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error = %v, want %v", err, context.Canceled)
+		}
+		if b.Len() != 0 {
+			t.Errorf("output = %v, want nothing", b.String())
+		}
+	})
+
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("cancelled while running with %d workers", workers), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			options := ExecuteOptions{
+				Workers:       workers,
+				ProgressEvery: 100,
+				Progress: func(p Progress) {
+					if p.RowsRead >= 1000 {
+						cancel()
+					}
+				},
+			}
+
+			var b bytes.Buffer
+			_, err := transformation.ExecuteContext(ctx, csv.NewReader(strings.NewReader(input.String())), csv.NewWriter(&b), false, -1, options)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("error = %v, want %v", err, context.Canceled)
+			}
+			// rows are written in order, so the output is always the first rows of the input
+			want := input.String()[:strings.Index(input.String(), "1001\n")]
+			if got := b.String(); got != want {
+				t.Errorf("output has %d rows, want the first 1000", strings.Count(got, "\n"))
+			}
+		})
+	}
+}
@@ -1,12 +1,14 @@
 package recipe
 
 import (
-	"encoding/csv"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
+
+	"github.com/dstockto/csv-chef/csv"
 )
 
 type Output struct {
@@ -37,6 +39,7 @@ func (o *Output) GetValue(ctx LineContext) (string, error) {
 type Argument struct {
 	Type  DataType
 	Value string
+	Pipe  []Operation // operations that work out the value of an Expression argument
 }
 
 func (a *Argument) GetValue(context LineContext, placeholder string) (string, error) {
@@ -50,15 +53,34 @@ func (a *Argument) GetValue(context LineContext, placeholder string) (string, er
 		}
 		value = colValue
 	case Variable:
+		if isParam(a.Value) {
+			paramValue, ok := context.params[paramName(a.Value)]
+			if !ok {
+				return "", fmt.Errorf("parameter '%s' referenced, but it is not set", a.Value)
+			}
+			return paramValue, nil
+		}
 		varValue, ok := context.Variables[a.Value]
 		if !ok {
 			return "", fmt.Errorf("variable '%s' referenced, but it is not defined", a.Value)
 		}
 		value = varValue
+	case NamedColumn:
+		colNum, ok := context.names[a.Value]
+		if !ok {
+			return "", fmt.Errorf("column %s referenced, but it was not found in the header", ColumnNameRef(a.Value))
+		}
+		colValue, ok := context.Columns[colNum]
+		if !ok {
+			return "", fmt.Errorf("column %s (%d) referenced, but it does not exist in the input", ColumnNameRef(a.Value), colNum)
+		}
+		value = colValue
 	case Literal:
 		return a.Value, nil
 	case Placeholder:
 		return placeholder, nil
+	case Expression:
+		return "", fmt.Errorf("argument %s is an expression, it is worked out by the transformation", a.Value)
 	default:
 		return "", fmt.Errorf("argument GetValue not implemented for type %s", a.Type.String())
 	}
@@ -82,11 +104,54 @@ type Transformation struct {
 	Columns       map[int]Recipe
 	Headers       map[int]Recipe
 	VariableOrder []string
+	Filters       []Recipe          // a row is only written if every filter is true, see IsTrue
+	Functions     *FunctionRegistry // functions available to the recipe, nil uses the DefaultRegistry
+	Lookups       map[string]*Lookup
+	InputDialect  csv.Dialect           // how the input and lookup files are laid out, set with @input
+	InputEncoding string                // character encoding of the input and lookup files, see csv.NewDecoder
+	OutputDialect csv.Dialect           // how the output is laid out, set with @output
+	Types         map[int]csv.ValueType // types of the output columns, set with @type
+	Fields        []string              // field paths that are the columns of JSON input, set with @fields
+	Params        map[string]string     // values of the parameters by name, read in the recipe as $param.name
+	Dir           string                // directory of the recipe file, files named in the recipe are relative to it
+	declared      map[string]bool       // parameters declared with @param
+}
+
+// execution is what a single run of a Transformation works out for itself, so running it doesn't change the
+// Transformation.
+type execution struct {
+	names         map[string]int // input column numbers by header name, found in the header
+	splitVariable string         // variable added to the end of each output row for a SplitWriter
+	explodes      bool           // the recipe explodes lines into several rows
 }
 
 type TransformationResult struct {
 	HeaderLines int
 	Lines       int
+	Kept        int         // rows that passed the filters and were written, or added to a group
+	Skipped     int         // lines removed by the filters
+	Rejected    int         // lines that had an error and were skipped or rejected
+	Groups      int         // rows written for a grouped recipe, one per group
+	Files       []SplitFile // rows written to each file when the output is split by a SplitWriter
+}
+
+// RowReader supplies the input rows of a Transformation. The readers from encoding/csv and the csv package are both
+// RowReaders.
+type RowReader interface {
+	Read() ([]string, error)
+}
+
+// RowWriter receives the output rows of a Transformation. The writers from encoding/csv and the csv package are both
+// RowWriters.
+type RowWriter interface {
+	Write(row []string) error
+	Flush()
+}
+
+// RowChecker is implemented by a RowWriter that can refuse a row it isn't able to write, like a value that is too
+// long for a fixed-width field. A refused row is handled like any other error in a row, according to the ErrorMode.
+type RowChecker interface {
+	CheckRow(row []string) error
 }
 
 func (t *Transformation) Dump(w io.Writer) {
@@ -120,6 +185,37 @@ func (t *Transformation) Dump(w io.Writer) {
 		_, _ = fmt.Fprintf(w, "Comment: %s\n---\n", v.Comment)
 	}
 
+	if len(t.Filters) > 0 {
+		_, _ = fmt.Fprintln(w, "Filters: \n======")
+		for _, f := range t.Filters {
+			_, _ = fmt.Fprintf(w, "Filter: %s\n", f.Output.Value)
+			_, _ = fmt.Fprint(w, "pipe: ")
+			for _, p := range f.Pipe {
+				_, _ = fmt.Fprint(w, p.Name+"(")
+				for _, a := range p.Arguments {
+					_, _ = fmt.Fprintf(w, "%s: %s, ", a.Type.String(), a.Value)
+				}
+				_, _ = fmt.Fprintf(w, ") -> ")
+			}
+			_, _ = fmt.Fprintln(w)
+			_, _ = fmt.Fprintf(w, "Comment: %s\n---\n", f.Comment)
+		}
+	}
+
+	if len(t.Lookups) > 0 {
+		_, _ = fmt.Fprintln(w, "Lookups: \n======")
+		for _, l := range t.Lookups {
+			_, _ = fmt.Fprintf(w, "Lookup: %s\nfile: %s, key: %d", l.Name, l.Path, l.KeyColumn)
+			if l.HasDefault {
+				_, _ = fmt.Fprintf(w, ", default: %s", l.Default)
+			}
+			if l.NoHeader {
+				_, _ = fmt.Fprint(w, ", no header")
+			}
+			_, _ = fmt.Fprint(w, "\n---\n")
+		}
+	}
+
 	_, _ = fmt.Fprintln(w)
 	_, _ = fmt.Fprintln(w, "Columns: \n======")
 	for _, c := range t.Columns {
@@ -157,6 +253,13 @@ func (t *Transformation) AddOutputToColumn(column string) error {
 	return nil
 }
 
+// AddFilter adds a new, empty filter and returns the filter number to use as the target for its operations.
+func (t *Transformation) AddFilter() string {
+	filter := strconv.Itoa(len(t.Filters) + 1)
+	t.Filters = append(t.Filters, Recipe{Output: getOutputForFilter(filter)})
+	return filter
+}
+
 func (t *Transformation) AddOutputToHeader(header string) error {
 	output := getOutputForHeader(header)
 	headerNum, _ := strconv.Atoi(header)
@@ -168,385 +271,401 @@ func (t *Transformation) AddOutputToHeader(header string) error {
 	return nil
 }
 
-func (t *Transformation) Execute(reader *csv.Reader, writer *csv.Writer, processHeader bool, lineLimit int) (*TransformationResult, error) {
-	defer writer.Flush()
+// ExecuteOptions controls how a Transformation is executed.
+type ExecuteOptions struct {
+	// Workers is the number of goroutines used to transform rows. Output is always written in input order.
+	// Zero or one transforms rows serially.
+	Workers int
+	// OnError decides what happens to a row that cannot be read or transformed. The header row always fails.
+	OnError ErrorMode
+	// Rejects receives the rows that failed when OnError is Reject.
+	Rejects RowWriter
+	// Progress, if set, is called every ProgressEvery rows read and once more when execution finishes. It is called
+	// from the goroutine writing the output, so it should return quickly.
+	Progress func(Progress)
+	// ProgressEvery is the number of rows between calls to Progress, 1000 if not set.
+	ProgressEvery int
+}
 
-	numColumns := len(t.Columns)
+func (t *Transformation) Execute(reader RowReader, writer RowWriter, processHeader bool, lineLimit int) (*TransformationResult, error) {
+	return t.ExecuteWithOptions(reader, writer, processHeader, lineLimit, ExecuteOptions{})
+}
+
+func (t *Transformation) ExecuteWithOptions(reader RowReader, writer RowWriter, processHeader bool, lineLimit int, options ExecuteOptions) (*TransformationResult, error) {
+	return t.ExecuteContext(context.Background(), reader, writer, processHeader, lineLimit, options)
+}
+
+// ExecuteContext transforms the rows from reader and writes them to writer. The context is checked between rows, if
+// it is cancelled the rows written so far are flushed and the context's error is returned.
+func (t *Transformation) ExecuteContext(ctx context.Context, reader RowReader, writer RowWriter, processHeader bool, lineLimit int, options ExecuteOptions) (*TransformationResult, error) {
+	defer writer.Flush()
 
 	if err := t.ValidateRecipe(); err != nil {
 		return nil, err
 	}
+	if !processHeader {
+		if err := t.requireNoNames(); err != nil {
+			return nil, err
+		}
+	}
+	if err := t.LoadLookups(); err != nil {
+		return nil, err
+	}
+	if options.OnError == Reject {
+		if options.Rejects == nil {
+			return nil, errors.New("a rejects writer is required to reject rows")
+		}
+		defer options.Rejects.Flush()
+	}
+	run := &execution{explodes: t.explodes()}
+	if split, ok := writer.(*SplitWriter); ok {
+		run.splitVariable = split.variable
+	}
 	var linesRead int
+	var headerLines int
 
-	for {
-		if lineLimit > 0 && linesRead >= lineLimit {
-			break
-		}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if processHeader {
 		row, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
+		if err != nil && err != io.EOF {
 			return nil, err
 		}
-		linesRead++
+		if err == nil {
+			linesRead++
+			headerLines = 1
 
-		var context = LineContext{
-			Variables: map[string]string{},
-			Columns:   map[int]string{},
-			LineNo:    linesRead,
-		}
-		// Load context with all the columns
-		for i, v := range row {
-			context.Columns[i+1] = v
-		}
+			if run.names, err = t.resolveNames(row); err != nil {
+				return nil, err
+			}
 
-		// process variables
-		for _, v := range t.VariableOrder {
-			variableName := t.Variables[v].Output.Value
-			variableRecipe := t.Variables[v]
-			placeholder, err := t.processRecipe("variable", variableRecipe, context)
+			output, err := t.transformHeader(run, row, linesRead, inputFilename(reader))
 			if err != nil {
 				return nil, err
 			}
-			context.Variables[variableName] = placeholder
-		}
-
-		if processHeader && linesRead == 1 {
-			// Load existing headers up to size of output
-			var output = make(map[int]string)
-			for i := 1; i <= numColumns; i++ {
-				var value string
-				if i <= len(row) {
-					value = row[i-1]
-				} else {
-					value = fmt.Sprintf("column %d", i)
-				}
-				output[i] = value
+			if err := writer.Write(output); err != nil {
+				return nil, err
 			}
-
-			for h := range t.Headers {
-				headerRecipe := t.Headers[h]
-				placeholder, err := t.processRecipe("header", headerRecipe, context)
-				if err != nil {
+			if options.OnError == Reject {
+				rejectsHeader := append(append([]string(nil), row...), "line", "target", "error")
+				if err := options.Rejects.Write(rejectsHeader); err != nil {
 					return nil, err
 				}
-				output[h] = placeholder
 			}
+		}
+	}
 
-			err := t.outputCsvRow(numColumns, output, writer)
-			if err != nil {
-				return nil, err
-			}
+	result := TransformationResult{HeaderLines: headerLines}
+	progress := newProgressReporter(options)
+
+	// a grouped recipe collects the rows into groups and writes them at the end
+	var rows RowWriter = writer
+	var grouped *groups
+	if t.IsGrouped() {
+		grouped = t.newGroups()
+		rows = grouped
+	}
+
+	var err error
+	if options.Workers > 1 {
+		linesRead, err = t.executeParallel(ctx, run, reader, rows, linesRead, lineLimit, options, &result, progress)
+	} else {
+		linesRead, err = t.executeSerial(ctx, run, reader, rows, linesRead, lineLimit, options, &result, progress)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rowsWritten := headerLines + result.Kept
+	if grouped != nil {
+		result.Groups, err = grouped.writeTo(writer)
+		if err != nil {
+			return nil, err
 		}
+		rowsWritten = headerLines + result.Groups
+	}
 
-		if !processHeader || linesRead > 1 {
-			var output = make(map[int]string)
+	result.Lines = linesRead - headerLines
+	if split, ok := writer.(*SplitWriter); ok {
+		result.Files = split.Files()
+	}
+	progress.report(Progress{RowsRead: linesRead, RowsWritten: rowsWritten, BytesRead: inputOffset(reader)})
 
-			for c := range t.Columns {
-				columnRecipe := t.Columns[c]
-				placeholder, err := t.processRecipe("column", columnRecipe, context)
-				if err != nil {
-					return nil, err
-				}
-				output[c] = placeholder
-			}
+	return &result, nil
+}
 
-			err = t.outputCsvRow(numColumns, output, writer)
-			if err != nil {
-				return nil, err
-			}
+func (t *Transformation) executeSerial(ctx context.Context, run *execution, reader RowReader, writer RowWriter, linesRead int, lineLimit int, options ExecuteOptions, result *TransformationResult, progress *progressReporter) (int, error) {
+	for {
+		if lineLimit > 0 && linesRead >= lineLimit {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return linesRead, err
 		}
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && (options.OnError == Fail || !isRowReadError(err)) {
+			return linesRead, err
+		}
+		linesRead++
 
+		var outputs [][]string
+		if err == nil {
+			outputs, err = t.transformRow(run, row, linesRead, inputFilename(reader))
+		}
+		if err == nil {
+			err = checkRows(writer, outputs, linesRead)
+		}
+		if err != nil {
+			if err := t.rejectRow(options, row, linesRead, err, result); err != nil {
+				return linesRead, err
+			}
+		} else if err := writeRows(writer, outputs, result); err != nil {
+			return linesRead, err
+		}
 		if linesRead%100 == 0 {
 			writer.Flush()
 		}
+		progress.update(Progress{RowsRead: linesRead, RowsWritten: result.HeaderLines + result.Kept, BytesRead: inputOffset(reader)})
 	}
 
-	var headerLines int
-	if processHeader {
-		headerLines = 1
+	return linesRead, nil
+}
+
+// newLineContext loads the columns of the row into a context and processes the variables for it
+func (t *Transformation) newLineContext(run *execution, row []string, lineNo int, filename string, subIndex int, explosion *explosion) (LineContext, error) {
+	var context = LineContext{
+		Variables: map[string]string{},
+		Columns:   map[int]string{},
+		LineNo:    lineNo,
+		Filename:  filename,
+		SubIndex:  subIndex,
+		explosion: explosion,
+		lookups:   t.Lookups,
+		names:     run.names,
+		params:    t.Params,
+	}
+	// Load context with all the columns
+	for i, v := range row {
+		context.Columns[i+1] = v
 	}
 
-	result := TransformationResult{
-		Lines:       linesRead - headerLines,
-		HeaderLines: headerLines,
+	// process variables
+	for _, v := range t.VariableOrder {
+		variableName := t.Variables[v].Output.Value
+		variableRecipe := t.Variables[v]
+		placeholder, err := t.processRecipe("variable", variableRecipe, context)
+		if err != nil {
+			return context, err
+		}
+		context.Variables[variableName] = placeholder
 	}
 
-	return &result, nil
+	return context, nil
 }
 
-func (t *Transformation) outputCsvRow(numColumns int, output map[int]string, writer *csv.Writer) error {
-	var outputRow []string
+// transformHeader builds the output header row. Columns without a header recipe keep the existing header, or are
+// named by their position if the input does not have that many columns.
+func (t *Transformation) transformHeader(run *execution, row []string, lineNo int, filename string) ([]string, error) {
+	context, err := t.newLineContext(run, row, lineNo, filename, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	numColumns := len(t.Columns)
+	output := make([]string, numColumns)
 	for i := 1; i <= numColumns; i++ {
-		outputRow = append(outputRow, output[i])
+		if i <= len(row) {
+			output[i-1] = row[i-1]
+		} else {
+			output[i-1] = fmt.Sprintf("column %d", i)
+		}
+	}
+
+	for h := range t.Headers {
+		headerRecipe := t.Headers[h]
+		placeholder, err := t.processRecipe("header", headerRecipe, context)
+		if err != nil {
+			return nil, err
+		}
+		output[h-1] = placeholder
+	}
+
+	return output, nil
+}
+
+// transformRow builds the output rows for a row of input. There is one output row unless the recipe explodes a
+// value into several, in which case the whole recipe is run again for each part. Rows removed by the filters are nil.
+// It only reads from the Transformation, so it is safe to call from more than one goroutine.
+func (t *Transformation) transformRow(run *execution, row []string, lineNo int, filename string) ([][]string, error) {
+	explosion := &explosion{rows: 1}
+	var outputs [][]string
+	for subIndex := 1; subIndex <= explosion.rows; subIndex++ {
+		output, err := t.transformSubRow(run, row, lineNo, filename, subIndex, explosion)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output)
 	}
-	err := writer.Write(outputRow)
+
+	return outputs, nil
+}
+
+// transformSubRow builds a single output row, or nil if the filters remove it.
+func (t *Transformation) transformSubRow(run *execution, row []string, lineNo int, filename string, subIndex int, explosion *explosion) ([]string, error) {
+	context, err := t.newLineContext(run, row, lineNo, filename, subIndex, explosion)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	keep := true
+	for _, filterRecipe := range t.Filters {
+		value, err := t.processRecipe("filter", filterRecipe, context)
+		if err != nil {
+			return nil, err
+		}
+		if !IsTrue(value) {
+			keep = false
+			break
+		}
+	}
+	// the columns of the first row are still worked out when it is removed, as they decide how many rows the line is
+	// exploded into and the filters may keep the others
+	if !keep && (subIndex > 1 || !run.explodes) {
+		return nil, nil
 	}
+
+	numColumns := len(t.Columns)
+	output := make([]string, numColumns)
+	for c := 1; c <= numColumns; c++ {
+		columnRecipe := t.Columns[c]
+		placeholder, err := t.processRecipe("column", columnRecipe, context)
+		if err != nil && !keep && !t.recipeExplodes(columnRecipe) {
+			// the row is removed, so only an error in a column that explodes it matters
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		output[c-1] = placeholder
+	}
+	if !keep {
+		return nil, nil
+	}
+
+	if err := t.checkTypes(output, lineNo); err != nil {
+		return nil, err
+	}
+	if run.splitVariable != "" {
+		output = append(output, context.Variables[run.splitVariable])
+	}
+
+	return output, nil
+}
+
+// checkRows makes sure the writer is able to write every output row for a line of input, before any of them are
+// written.
+func checkRows(writer RowWriter, outputs [][]string, lineNo int) error {
+	checker, ok := writer.(RowChecker)
+	if !ok {
+		return nil
+	}
+	for _, output := range outputs {
+		if output == nil {
+			continue
+		}
+		if err := checker.CheckRow(output); err != nil {
+			return &RowError{LineNo: lineNo, Target: "output", Err: err}
+		}
+	}
+	return nil
+}
+
+// writeRows writes the output rows for a line of input. The line is skipped if the filters removed all of its rows.
+func writeRows(writer RowWriter, outputs [][]string, result *TransformationResult) error {
+	kept := 0
+	for _, output := range outputs {
+		if output == nil {
+			continue
+		}
+		if err := writer.Write(output); err != nil {
+			return err
+		}
+		kept++
+	}
+	if kept == 0 {
+		result.Skipped++
+	}
+	result.Kept += kept
 	return nil
 }
 
+func (t *Transformation) registry() *FunctionRegistry {
+	if t.Functions == nil {
+		return DefaultRegistry
+	}
+	return t.Functions
+}
+
 func (t *Transformation) processRecipe(recipeType string, variable Recipe, context LineContext) (string, error) {
-	var placeholder string
+	value, err := t.runPipe(variable.Pipe, context, "")
+	if err != nil {
+		return "", &RowError{LineNo: context.LineNo, Target: recipeType + " " + variable.Output.Value, Err: err}
+	}
+	return value, nil
+}
+
+// runPipe works out the operations of a pipe in order, starting with the given placeholder, and returns the value of
+// the last one.
+func (t *Transformation) runPipe(pipe []Operation, context LineContext, placeholder string) (string, error) {
 	var value string
 	mode := Replace
 
-	errorPrefix := fmt.Sprintf("line %d / %s %s:", context.LineNo, recipeType, variable.Output.Value)
-
-	for _, o := range variable.Pipe {
+	for _, o := range pipe {
 		opName := strings.ToLower(o.Name)
 		switch opName {
 		case "value":
 			firstArg := o.Arguments[0]
-			argValue, err := firstArg.GetValue(context, placeholder)
+			argValue, err := t.argValue(firstArg, context, placeholder)
 			if err != nil {
-				return "", fmt.Errorf("%s %v", errorPrefix, err)
+				return "", err
 			}
 			value = argValue
 		case "join":
+			// join is registered so it can be documented and validated, but it changes the join mode so it is
+			// handled here instead of through the registry
 			firstArg := o.Arguments[0]
 			mode = Join
-			argValue, err := firstArg.GetValue(context, placeholder)
+			argValue, err := t.argValue(firstArg, context, placeholder)
 			if err != nil {
-				return "", fmt.Errorf("%s %v", errorPrefix, err)
+				return "", err
 			}
 			value = argValue
 			// If the argument is placeholder then there's something coming after
 			if firstArg.Type == Placeholder {
 				continue
 			}
-		case "uppercase":
-			firstArg, err := o.Arguments[0].GetValue(context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			value = Uppercase(firstArg)
-		case "lowercase":
-			firstArg, err := o.Arguments[0].GetValue(context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			value = Lowercase(firstArg)
-		case "add":
-			args, err := processArgs(2, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			sum, err := Add(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = sum
-		case "subtract":
-			args, err := processArgs(2, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			difference, err := Subtract(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = difference
-		case "multiply":
-			args, err := processArgs(2, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			product, err := Multiply(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = product
-		case "divide":
-			args, err := processArgs(2, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			product, err := Divide(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = product
-		case "change":
-			args, err := processArgs(3, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			updated, _ := Change(args[0], args[1], args[2]) // no errors from this
-			value = updated
-		case "changei":
-			args, err := processArgs(3, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			updated, _ := ChangeI(args[0], args[1], args[2]) // no errors from this
-			value = updated
-		case "ifempty", "isempty":
-			args, err := processArgs(3, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, _ := IfEmpty(args[0], args[1], args[2]) // no errors
-			value = result
-		case "numberformat":
-			args, err := processArgs(3, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, err := NumberFormat(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = result
-		case "lineno":
-			value = strconv.Itoa(context.LineNo)
-		case "removedigits":
-			args, err := processArgs(1, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, _ := RemoveDigits(args[0]) // no errors from this
-			value = result
-		case "onlydigits":
-			args, err := processArgs(1, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, _ := OnlyDigits(args[0]) // no errors from this
-			value = result
-		case "mod":
-			args, err := processArgs(2, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, err := Modulus(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = result
-		case "trim":
-			args, err := processArgs(1, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, _ := Trim(args[0])
-			value = result
-		case "firstchars":
-			args, err := processArgs(2, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, err := FirstChars(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = result
-		case "lastchars":
-			args, err := processArgs(2, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, err := LastChars(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = result
-		case "repeat":
-			args, err := processArgs(2, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, err := Repeat(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = result
-		case "replace":
-			args, err := processArgs(3, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, _ := ReplaceString(args[0], args[1], args[2]) // no errors from this
-			value = result
-		case "today":
-			value, _ = Today(Now)
-		case "now":
-			value, _ = NowTime(Now)
-		case "formatdate":
-			args, err := processArgs(2, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, err := FormatDate(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = result
-		case "formatdatef":
-			args, err := processArgs(2, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, err := FormatDateF(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = result
-		case "readdate":
-			args, err := processArgs(2, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, err := ReadDate(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = result
-		case "readdatef":
-			args, err := processArgs(2, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, err := ReadDateF(args[0], args[1])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = result
-		case "smartdate":
-			args, err := processArgs(1, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
+		default:
+			function, ok := t.registry().Lookup(opName)
+			if !ok {
+				return "", fmt.Errorf("error: processing variable, unimplemented operation %s", o.Name)
 			}
-			result, err := SmartDate(args[0])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
+			numArgs := len(function.Args)
+			if function.Variadic {
+				numArgs = len(o.Arguments)
 			}
-			value = result
-		case "ispast":
-			args, err := processArgs(3, o.Arguments, context, placeholder)
+			args, err := t.processArgs(numArgs, o.Arguments, context, placeholder)
 			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
+				return "", fmt.Errorf("%s(): error evaluating arg: %v", opName, err)
 			}
-			result, err := IsPast(args[0], args[1], args[2])
+			result, err := function.Impl(context, args)
 			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
+				return "", fmt.Errorf("%s(): %v", opName, err)
 			}
 			value = result
-		case "isfuture":
-			args, err := processArgs(3, o.Arguments, context, placeholder)
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): error evaluating arg: %v", errorPrefix, opName, err)
-			}
-			result, err := IsFuture(args[0], args[1], args[2])
-			if err != nil {
-				return "", fmt.Errorf("%s %s(): %v", errorPrefix, opName, err)
-			}
-			value = result
-		// TODO make function calling more smart, using the allFuncs thing
-		default:
-			return "", fmt.Errorf("%s error: processing variable, unimplemented operation %s", errorPrefix, o.Name)
 		}
 
 		switch mode {
@@ -562,7 +681,7 @@ func (t *Transformation) processRecipe(recipeType string, variable Recipe, conte
 	return placeholder, nil
 }
 
-func processArgs(numArgs int, arguments []Argument, context LineContext, placeholder string) ([]string, error) {
+func (t *Transformation) processArgs(numArgs int, arguments []Argument, context LineContext, placeholder string) ([]string, error) {
 	for len(arguments) < numArgs {
 		arguments = append(arguments, getPlaceholderArg())
 	}
@@ -570,7 +689,7 @@ func processArgs(numArgs int, arguments []Argument, context LineContext, placeho
 	var processedArgs []string
 
 	for i := 0; i < numArgs; i++ {
-		value, err := arguments[i].GetValue(context, placeholder)
+		value, err := t.argValue(arguments[i], context, placeholder)
 		if err != nil {
 			return []string{}, err
 		}
@@ -580,6 +699,15 @@ func processArgs(numArgs int, arguments []Argument, context LineContext, placeho
 	return processedArgs, nil
 }
 
+// argValue returns the value of an argument. An Expression argument runs its own pipe, starting with the placeholder
+// of the operation it belongs to.
+func (t *Transformation) argValue(a Argument, context LineContext, placeholder string) (string, error) {
+	if a.Type == Expression {
+		return t.runPipe(a.Pipe, context, placeholder)
+	}
+	return a.GetValue(context, placeholder)
+}
+
 func getPlaceholderArg() Argument {
 	return Argument{
 		Type:  Placeholder,
@@ -634,6 +762,34 @@ func (t *Transformation) AddOperationToHeader(header string, operation Operation
 	t.Headers[headerNumber] = recipe
 }
 
+func (t *Transformation) AddOperationToFilter(filter string, operation Operation) {
+	filterNumber, _ := strconv.Atoi(filter)
+	for len(t.Filters) < filterNumber {
+		t.AddFilter()
+	}
+	recipe := t.Filters[filterNumber-1]
+	recipe.Pipe = append(recipe.Pipe, operation)
+	t.Filters[filterNumber-1] = recipe
+}
+
+// getRecipeByType returns the recipe for a target, as used when parsing.
+func (t *Transformation) getRecipeByType(targetType DataType, target string) Recipe {
+	switch targetType {
+	case Variable:
+		return t.Variables[target]
+	case Column:
+		columnNum, _ := strconv.Atoi(target)
+		return t.Columns[columnNum]
+	case Header:
+		headerNum, _ := strconv.Atoi(target)
+		return t.Headers[headerNum]
+	case Filter:
+		filterNum, _ := strconv.Atoi(target)
+		return t.Filters[filterNum-1]
+	}
+	return Recipe{}
+}
+
 func (t *Transformation) AddOperationByType(targetType DataType, target string, operation Operation) {
 	switch targetType {
 	case Variable:
@@ -642,7 +798,39 @@ func (t *Transformation) AddOperationByType(targetType DataType, target string,
 		t.AddOperationToColumn(target, operation)
 	case Header:
 		t.AddOperationToHeader(target, operation)
+	case Filter:
+		t.AddOperationToFilter(target, operation)
+	}
+}
+
+// forEachOperation calls fn for every operation of the pipe, and of the pipes of its Expression arguments.
+func forEachOperation(pipe []Operation, fn func(o Operation)) {
+	for _, o := range pipe {
+		fn(o)
+		for _, a := range o.Arguments {
+			if a.Type == Expression {
+				forEachOperation(a.Pipe, fn)
+			}
+		}
+	}
+}
+
+// allRecipes returns every recipe in the transformation.
+func (t *Transformation) allRecipes() []Recipe {
+	var recipes []Recipe
+	for _, v := range t.VariableOrder {
+		recipes = append(recipes, t.Variables[v])
+	}
+	recipes = append(recipes, t.Filters...)
+	for c := 1; c <= len(t.Columns); c++ {
+		recipes = append(recipes, t.Columns[c])
+	}
+	for h := 1; h <= len(t.Columns); h++ {
+		if r, ok := t.Headers[h]; ok {
+			recipes = append(recipes, r)
+		}
 	}
+	return recipes
 }
 
 func (t *Transformation) ValidateRecipe() error {
@@ -674,6 +862,15 @@ type LineContext struct {
 	Variables map[string]string
 	Columns   map[int]string
 	LineNo    int
+	// Filename is the path of the file the row was read from, when the reader is able to say.
+	Filename string
+	// SubIndex is the position of the row among the rows exploded from the same line of input, starting at 1. It
+	// is 0 for the header.
+	SubIndex  int
+	explosion *explosion
+	lookups   map[string]*Lookup
+	names     map[string]int
+	params    map[string]string
 }
 
 func NewTransformation() *Transformation {
@@ -800,3 +800,89 @@ func TestTransformation_Execute(t1 *testing.T) {
 		})
 	}
 }
+
+func TestTransformation_ExecuteFilterResult(t *testing.T) {
+	transformation, err := Parse(strings.NewReader("1 <- 1\n? <- 2 -> eq(\"y\")\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+	var b bytes.Buffer
+	input := "name,keep\na,y\nb,n\nc,y\nd,n\ne,n\n"
+	got, err := transformation.Execute(csv.NewReader(strings.NewReader(input)), csv.NewWriter(&b), true, -1)
+	if err != nil {
+		t.Fatalf("execute error = %v", err)
+	}
+	want := TransformationResult{HeaderLines: 1, Lines: 5, Kept: 2, Skipped: 3}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("Execute() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestTransformation_ExecuteFilterExplodedResult(t *testing.T) {
+	// the first part of each line is removed, the line is only skipped when none of its parts are kept
+	transformation, err := Parse(strings.NewReader("1 <- 1 -> explode(\";\")\n2 <- subindex\n? <- subindex -> ne(\"1\")\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+	var b bytes.Buffer
+	input := "a;b;c\nd\ne;f\n"
+	got, err := transformation.Execute(csv.NewReader(strings.NewReader(input)), csv.NewWriter(&b), false, -1)
+	if err != nil {
+		t.Fatalf("execute error = %v", err)
+	}
+	if want := "b,2\nc,3\nf,2\n"; b.String() != want {
+		t.Errorf("output = %q, want %q", b.String(), want)
+	}
+	want := TransformationResult{Lines: 3, Kept: 3, Skipped: 1}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("Execute() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestTransformation_ExecuteFilterExplodedErrors(t *testing.T) {
+	// column 1 fails for the first part, which is removed, but the line is still exploded by column 2
+	recipe := "1 <- subindex -> subtract(\"1\") -> divide(\"6\")\n2 <- 1 -> explode(\";\")\n? <- subindex -> ne(\"1\")\n"
+	transformation, err := Parse(strings.NewReader(recipe))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+	var b bytes.Buffer
+	_, err = transformation.Execute(csv.NewReader(strings.NewReader("a;b;c\n")), csv.NewWriter(&b), false, -1)
+	if err != nil {
+		t.Fatalf("execute error = %v", err)
+	}
+	if want := "-6.000000,b\n-3.000000,c\n"; b.String() != want {
+		t.Errorf("output = %q, want %q", b.String(), want)
+	}
+
+	// when the column that explodes fails, the number of rows isn't known
+	transformation, err = Parse(strings.NewReader("1 <- \"0\" -> divide(\"1\") -> explode(\";\")\n? <- subindex -> ne(\"1\")\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+	_, err = transformation.Execute(csv.NewReader(strings.NewReader("1\n")), csv.NewWriter(&b), false, -1)
+	if err == nil {
+		t.Errorf("execute error = nil, want the error of the column that explodes")
+	}
+}
+
+func TestTransformation_DumpFilters(t *testing.T) {
+	tests := []struct {
+		recipe      string
+		wantFilters bool
+	}{
+		{recipe: "1 <- 1\n", wantFilters: false},
+		{recipe: "1 <- 1\n? <- 2 # keep\n", wantFilters: true},
+	}
+	for _, tt := range tests {
+		transformation, err := Parse(strings.NewReader(tt.recipe))
+		if err != nil {
+			t.Fatalf("parse error = %v", err)
+		}
+		var b bytes.Buffer
+		transformation.Dump(&b)
+		if got := strings.Contains(b.String(), "Filters:"); got != tt.wantFilters {
+			t.Errorf("Dump() of %q shows filters = %v, want %v:\n%s", tt.recipe, got, tt.wantFilters, b.String())
+		}
+	}
+}
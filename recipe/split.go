@@ -0,0 +1,187 @@
+package recipe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CreateFunc creates the writer for one of the files of split output. The key is the value the rows were split by,
+// and part counts the files for the key starting at 1. The returned function finishes and closes the file.
+type CreateFunc func(key string, part int) (RowWriter, func() error, error)
+
+// SplitFile is the number of rows written to one of the files of split output, not counting the header.
+type SplitFile struct {
+	Key  string
+	Part int
+	Rows int
+}
+
+// SplitWriter is a RowWriter that spreads the output over several files, one for each value of an output column or
+// variable, and starts a new file when one reaches the maximum number of rows. The first row is the header, which is
+// written at the top of every file. Pass it to Execute in place of a single writer, then Close it.
+type SplitWriter struct {
+	column   int    // output column the rows are split by, 0-based, or -1
+	variable string // variable the rows are split by, Execute adds its value to the end of each row
+	maxRows  int
+	create   CreateFunc
+	check    RowChecker // checks rows for keys that don't have a file yet, or nil
+	header   []string
+	started  bool // the header has been seen, or there isn't one
+
+	current map[string]*splitPart
+	files   []SplitFile
+}
+
+type splitPart struct {
+	writer RowWriter
+	close  func() error
+	file   int // position in files
+}
+
+// NewSplitWriter returns a SplitWriter for the transformation. The rows are split by by, an output column number like
+// 3 or a variable like $state, or by nothing when it is empty. MaxRows limits the number of rows in each file, 0 for
+// no limit. When hasHeader is false, every row is data. Check, when it isn't nil, is a writer like the ones create
+// returns, which checks the rows for a key before its file is created, so a row that is refused doesn't leave an
+// empty file behind.
+func (t *Transformation) NewSplitWriter(by string, maxRows int, hasHeader bool, create CreateFunc, check RowChecker) (*SplitWriter, error) {
+	s := &SplitWriter{column: -1, maxRows: maxRows, create: create, check: check, started: !hasHeader, current: make(map[string]*splitPart)}
+	if maxRows < 0 {
+		return nil, fmt.Errorf("the maximum number of rows must not be negative, found %d", maxRows)
+	}
+
+	switch {
+	case by == "":
+	case strings.HasPrefix(by, "$"):
+		if _, ok := t.Variables[by]; !ok {
+			return nil, fmt.Errorf("can't split by %s, the recipe doesn't have that variable", by)
+		}
+		if t.IsGrouped() {
+			return nil, fmt.Errorf("a grouped recipe can only be split by an output column")
+		}
+		s.column = len(t.Columns)
+		s.variable = by
+	default:
+		column, err := strconv.Atoi(by)
+		if err != nil || column < 1 || column > len(t.Columns) {
+			return nil, fmt.Errorf("can't split by %s, expected an output column from 1 to %d or a variable", by, len(t.Columns))
+		}
+		s.column = column - 1
+	}
+	return s, nil
+}
+
+// key returns the value the row is split by.
+func (s *SplitWriter) key(row []string) string {
+	if s.column < 0 || s.column >= len(row) {
+		return ""
+	}
+	return row[s.column]
+}
+
+// strip removes the variable value that was added to the end of the row to split it by.
+func (s *SplitWriter) strip(row []string) []string {
+	if s.variable != "" && len(row) > s.column {
+		return row[:s.column]
+	}
+	return row
+}
+
+// part returns the file for the key, starting a new one when there isn't one yet or the last one is full.
+func (s *SplitWriter) part(key string) (*splitPart, error) {
+	p, ok := s.current[key]
+	if ok && (s.maxRows == 0 || s.files[p.file].Rows < s.maxRows) {
+		return p, nil
+	}
+
+	number := 1
+	if ok {
+		number = s.files[p.file].Part + 1
+		p.writer.Flush()
+		if err := p.close(); err != nil {
+			return nil, err
+		}
+		p.close = nil
+	}
+	writer, closeFile, err := s.create(key, number)
+	if err != nil {
+		return nil, err
+	}
+	if s.header != nil {
+		if err := writer.Write(s.header); err != nil {
+			_ = closeFile()
+			return nil, err
+		}
+	}
+	p = &splitPart{writer: writer, close: closeFile, file: len(s.files)}
+	s.current[key] = p
+	s.files = append(s.files, SplitFile{Key: key, Part: number})
+	return p, nil
+}
+
+// CheckRow makes sure the file the row goes to is able to write it, when its writer can say. A key without a file yet
+// is checked by the check writer, so no file is created for a row that is refused.
+func (s *SplitWriter) CheckRow(row []string) error {
+	checker := s.check
+	if p, ok := s.current[s.key(row)]; ok {
+		checker, _ = p.writer.(RowChecker)
+	}
+	if checker == nil {
+		return nil
+	}
+	return checker.CheckRow(s.strip(row))
+}
+
+// Write keeps the first row as the header, and writes each row after it to the file for its key.
+func (s *SplitWriter) Write(row []string) error {
+	if !s.started {
+		s.started = true
+		s.header = append([]string(nil), row...)
+		return nil
+	}
+	p, err := s.part(s.key(row))
+	if err != nil {
+		return err
+	}
+	if err := p.writer.Write(s.strip(row)); err != nil {
+		return err
+	}
+	s.files[p.file].Rows++
+	return nil
+}
+
+// Flush flushes the files that are still open.
+func (s *SplitWriter) Flush() {
+	for _, p := range s.current {
+		p.writer.Flush()
+	}
+}
+
+// Files returns the files written so far, in the order they were started.
+func (s *SplitWriter) Files() []SplitFile {
+	return append([]SplitFile(nil), s.files...)
+}
+
+// Close flushes, finishes and closes the files that are still open. When the rows aren't split by anything and there
+// weren't any, a file with just the header is written so there is still some output.
+func (s *SplitWriter) Close() error {
+	if len(s.files) == 0 && s.column < 0 {
+		if _, err := s.part(""); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	for _, f := range s.files {
+		p := s.current[f.Key]
+		if p == nil || p.close == nil {
+			continue
+		}
+		p.writer.Flush()
+		if cerr := p.close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		p.close = nil
+	}
+	return err
+}
@@ -0,0 +1,230 @@
+package recipe
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransformation_ExecuteSplit(t *testing.T) {
+	input := "state,city\nCO,Denver\nUT,Provo\nCO,Boulder\nCO,Aspen\n"
+
+	tests := []struct {
+		name        string
+		recipe      string
+		by          string
+		maxRows     int
+		noHeader    bool
+		want        map[string]string
+		wantFiles   []SplitFile
+		wantErrText string
+	}{
+		{
+			name:   "by column",
+			recipe: "1 <- 2\n2 <- 1\n",
+			by:     "2",
+			want: map[string]string{
+				"CO/1": "state,city\nDenver,CO\nBoulder,CO\nAspen,CO\n",
+				"UT/1": "state,city\nProvo,UT\n",
+			},
+			wantFiles: []SplitFile{{Key: "CO", Part: 1, Rows: 3}, {Key: "UT", Part: 1, Rows: 1}},
+		},
+		{
+			name:   "by variable",
+			recipe: "$s <- 1 -> lowercase\n1 <- 2\n",
+			by:     "$s",
+			want: map[string]string{
+				"co/1": "state\nDenver\nBoulder\nAspen\n",
+				"ut/1": "state\nProvo\n",
+			},
+			wantFiles: []SplitFile{{Key: "co", Part: 1, Rows: 3}, {Key: "ut", Part: 1, Rows: 1}},
+		},
+		{
+			name:    "by size",
+			recipe:  "1 <- 2\n",
+			maxRows: 3,
+			want: map[string]string{
+				"/1": "state\nDenver\nProvo\nBoulder\n",
+				"/2": "state\nAspen\n",
+			},
+			wantFiles: []SplitFile{{Part: 1, Rows: 3}, {Part: 2, Rows: 1}},
+		},
+		{
+			name:    "by column and size",
+			recipe:  "1 <- 1\n2 <- 2\n",
+			by:      "1",
+			maxRows: 2,
+			want: map[string]string{
+				"CO/1": "state,city\nCO,Denver\nCO,Boulder\n",
+				"UT/1": "state,city\nUT,Provo\n",
+				"CO/2": "state,city\nCO,Aspen\n",
+			},
+			wantFiles: []SplitFile{{Key: "CO", Part: 1, Rows: 2}, {Key: "UT", Part: 1, Rows: 1}, {Key: "CO", Part: 2, Rows: 1}},
+		},
+		{
+			name:     "without a header",
+			recipe:   "1 <- 1\n",
+			by:       "1",
+			noHeader: true,
+			want: map[string]string{
+				"state/1": "state\n",
+				"CO/1":    "CO\nCO\nCO\n",
+				"UT/1":    "UT\n",
+			},
+			wantFiles: []SplitFile{{Key: "state", Part: 1, Rows: 1}, {Key: "CO", Part: 1, Rows: 3}, {Key: "UT", Part: 1, Rows: 1}},
+		},
+		{
+			name:        "unknown column",
+			recipe:      "1 <- 1\n",
+			by:          "2",
+			wantErrText: "can't split by 2, expected an output column from 1 to 1 or a variable",
+		},
+		{
+			name:        "unknown variable",
+			recipe:      "1 <- 1\n",
+			by:          "$state",
+			wantErrText: "can't split by $state, the recipe doesn't have that variable",
+		},
+		{
+			name:        "grouped by variable",
+			recipe:      "$s <- 1\n1 <- 1\n2 <- count\n",
+			by:          "$s",
+			wantErrText: "a grouped recipe can only be split by an output column",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, workers := range []int{1, 4} {
+				transformation, err := Parse(strings.NewReader(tt.recipe))
+				if err != nil {
+					t.Fatalf("parse error = %v", err)
+				}
+
+				files := make(map[string]*bytes.Buffer)
+				create := func(key string, part int) (RowWriter, func() error, error) {
+					var b bytes.Buffer
+					name := fmt.Sprintf("%s/%d", key, part)
+					if _, ok := files[name]; ok {
+						return nil, nil, fmt.Errorf("%s was created twice", name)
+					}
+					files[name] = &b
+					w := csv.NewWriter(&b)
+					return w, w.Error, nil
+				}
+
+				split, err := transformation.NewSplitWriter(tt.by, tt.maxRows, !tt.noHeader, create, nil)
+				if tt.wantErrText != "" {
+					if err == nil || err.Error() != tt.wantErrText {
+						t.Errorf("error = %v, want %v", err, tt.wantErrText)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("split error = %v", err)
+				}
+
+				options := ExecuteOptions{Workers: workers}
+				result, err := transformation.ExecuteWithOptions(csv.NewReader(strings.NewReader(input)), split, !tt.noHeader, -1, options)
+				if err != nil {
+					t.Fatalf("workers %d: execute error = %v", workers, err)
+				}
+				if err := split.Close(); err != nil {
+					t.Fatalf("workers %d: close error = %v", workers, err)
+				}
+
+				got := make(map[string]string)
+				for name, b := range files {
+					got[name] = b.String()
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("workers %d: files = %q, want %q", workers, got, tt.want)
+				}
+				if !reflect.DeepEqual(result.Files, tt.wantFiles) {
+					t.Errorf("workers %d: result files = %+v, want %+v", workers, result.Files, tt.wantFiles)
+				}
+			}
+		})
+	}
+}
+
+func TestTransformation_NewSplitWriterLeavesTheRecipeAlone(t *testing.T) {
+	transformation, err := Parse(strings.NewReader("$s <- 1 -> lowercase\n1 <- 2\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+	create := func(key string, part int) (RowWriter, func() error, error) {
+		w := csv.NewWriter(&bytes.Buffer{})
+		return w, w.Error, nil
+	}
+	if _, err := transformation.NewSplitWriter("$s", 0, true, create, nil); err != nil {
+		t.Fatalf("split error = %v", err)
+	}
+
+	// the split variable is only added to the rows written to the SplitWriter
+	var out bytes.Buffer
+	writer := csv.NewWriter(&out)
+	if _, err := transformation.Execute(csv.NewReader(strings.NewReader("state,city\nCO,Denver\n")), writer, true, -1); err != nil {
+		t.Fatalf("execute error = %v", err)
+	}
+	if got, want := out.String(), "state\nDenver\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+// refusingWriter is a CSV writer that refuses rows with a value.
+type refusingWriter struct {
+	*csv.Writer
+	refuse string
+}
+
+func (w refusingWriter) CheckRow(row []string) error {
+	for _, value := range row {
+		if value == w.refuse {
+			return fmt.Errorf("value '%s' is refused", value)
+		}
+	}
+	return nil
+}
+
+func TestTransformation_ExecuteSplitRejectsBeforeCreatingFiles(t *testing.T) {
+	transformation, err := Parse(strings.NewReader("1 <- 1\n2 <- 2\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+	files := make(map[string]*bytes.Buffer)
+	create := func(key string, part int) (RowWriter, func() error, error) {
+		var b bytes.Buffer
+		files[key] = &b
+		w := refusingWriter{Writer: csv.NewWriter(&b), refuse: "Provo"}
+		return w, w.Error, nil
+	}
+	check := refusingWriter{Writer: csv.NewWriter(&bytes.Buffer{}), refuse: "Provo"}
+	split, err := transformation.NewSplitWriter("1", 0, true, create, check)
+	if err != nil {
+		t.Fatalf("split error = %v", err)
+	}
+
+	var rejects bytes.Buffer
+	options := ExecuteOptions{OnError: Reject, Rejects: csv.NewWriter(&rejects)}
+	input := "state,city\nCO,Denver\nUT,Provo\n"
+	result, err := transformation.ExecuteWithOptions(csv.NewReader(strings.NewReader(input)), split, true, -1, options)
+	if err != nil {
+		t.Fatalf("execute error = %v", err)
+	}
+	if err := split.Close(); err != nil {
+		t.Fatalf("close error = %v", err)
+	}
+
+	if _, ok := files["UT"]; ok {
+		t.Errorf("a file was created for the rejected row")
+	}
+	if want := []SplitFile{{Key: "CO", Part: 1, Rows: 1}}; !reflect.DeepEqual(result.Files, want) {
+		t.Errorf("result files = %+v, want %+v", result.Files, want)
+	}
+	if result.Rejected != 1 {
+		t.Errorf("rejected = %d, want 1", result.Rejected)
+	}
+}
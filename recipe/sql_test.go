@@ -0,0 +1,46 @@
+package recipe
+
+import (
+	"bytes"
+	encodingcsv "encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dstockto/csv-chef/csv"
+)
+
+func TestTransformation_ExecuteSQLTypesComeFromEveryRow(t *testing.T) {
+	// a value that isn't a number after the first 1000 rows makes the column text, it isn't an error
+	var input strings.Builder
+	input.WriteString("v\n")
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(&input, "%d\n", i)
+	}
+	input.WriteString("N/A\n")
+
+	transformation, err := Parse(strings.NewReader("1 <- 1\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+	var out bytes.Buffer
+	writer := csv.NewSQLWriter(&out, csv.SQLOptions{Table: "t", BatchSize: 1000})
+	result, err := transformation.Execute(encodingcsv.NewReader(strings.NewReader(input.String())), writer, true, -1)
+	if err != nil {
+		t.Fatalf("execute error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close error = %v", err)
+	}
+
+	if result.Kept != 1001 {
+		t.Errorf("kept = %d, want 1001", result.Kept)
+	}
+	got := out.String()
+	if wantStart := "CREATE TABLE \"t\" (\n  \"v\" TEXT\n);\n"; !strings.HasPrefix(got, wantStart) {
+		t.Errorf("output = %q, want it to start with %q", got, wantStart)
+	}
+	if wantEnd := "('1000');\nINSERT INTO \"t\" (\"v\") VALUES\n('N/A');\n"; !strings.HasSuffix(got, wantEnd) {
+		t.Errorf("output = %q, want it to end with %q", got, wantEnd)
+	}
+}
@@ -0,0 +1,31 @@
+package recipe
+
+import (
+	"bytes"
+	encodingcsv "encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/dstockto/csv-chef/csv"
+)
+
+func TestTransformation_ExecuteTable(t *testing.T) {
+	transformation, err := Parse(strings.NewReader("@type 1 number\n1 <- 1\n2 <- 2\n"))
+	if err != nil {
+		t.Fatalf("parse error = %v", err)
+	}
+
+	var out bytes.Buffer
+	writer := csv.NewMarkdownWriter(&out, csv.TableOptions{Types: transformation.Types})
+	reader := encodingcsv.NewReader(strings.NewReader("id,name\n1,\"A|b *c*\"\n2,\"<x> & \n y\"\n"))
+	if _, err := transformation.Execute(reader, writer, true, -1); err != nil {
+		t.Fatalf("execute error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close error = %v", err)
+	}
+	want := "| id | name |\n| ---: | --- |\n| 1 | A\\|b \\*c\\* |\n| 2 | \\<x\\> & <br> y |\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -22,4 +22,7 @@ const (
 	CLOSE_PAREN       //14 - )
 	COMMA             //15 - ,
 	HEADER            //16 - !<digits>
+	DIRECTIVE         //17 - starts w/ @
+	EQUALS            //18 - =
+	COLUMN_NAME       //19 - @"quoted"
 )
@@ -0,0 +1,35 @@
+package recipe
+
+import (
+	"fmt"
+
+	"github.com/dstockto/csv-chef/csv"
+)
+
+// validateTypes makes sure every column given a type with @type has a recipe.
+func (t *Transformation) validateTypes() error {
+	for column := range t.Types {
+		if _, ok := t.Columns[column]; !ok {
+			return fmt.Errorf("@type given for column %d, which has no recipe", column)
+		}
+	}
+	return nil
+}
+
+// checkTypes makes sure the output columns hold values of their type. Aggregate columns are checked when they are
+// written, since the value in the row is what is being aggregated and not the result.
+func (t *Transformation) checkTypes(output []string, lineNo int) error {
+	for c := 1; c <= len(output); c++ {
+		valueType, ok := t.Types[c]
+		if !ok {
+			continue
+		}
+		if _, ok := t.aggregateFunction(t.Columns[c]); ok {
+			continue
+		}
+		if err := csv.CheckValue(valueType, output[c-1]); err != nil {
+			return &RowError{LineNo: lineNo, Target: fmt.Sprintf("column %d", c), Err: err}
+		}
+	}
+	return nil
+}
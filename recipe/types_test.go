@@ -0,0 +1,70 @@
+package recipe
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dstockto/csv-chef/csv"
+)
+
+func TestTransformation_ExecuteJSON(t *testing.T) {
+	tests := []struct {
+		name             string
+		recipe           string
+		input            string
+		ndjson           bool
+		want             string
+		wantParseErrText string
+	}{
+		{
+			name:   "typed aggregates",
+			recipe: "@type 2 number\n1 <- 1\n2 <- 2 -> sum\n",
+			input:  "state,n\nCO,1\nCO,2\nUT,3\n",
+			ndjson: true,
+			want:   "{\"state\":\"CO\",\"n\":3}\n{\"state\":\"UT\",\"n\":3}\n",
+		},
+		{
+			name:             "unknown type",
+			recipe:           "@type 1 date\n1 <- 1\n",
+			wantParseErrText: "error - line 1: unrecognized type 'date', expected string, number or bool",
+		},
+		{
+			name:             "types need a column",
+			recipe:           "@type 2 number\n1 <- 1\n",
+			wantParseErrText: "@type given for column 2, which has no recipe",
+		},
+		{
+			name:             "one type per column",
+			recipe:           "@type 1 number\n@type 1 bool\n1 <- 1\n",
+			wantParseErrText: "error - line 2: type of column 1 already defined",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformation, err := Parse(strings.NewReader(tt.recipe))
+			if tt.wantParseErrText != "" {
+				if err == nil || err.Error() != tt.wantParseErrText {
+					t.Errorf("parse error = %v, want %v", err, tt.wantParseErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+
+			var b bytes.Buffer
+			writer := csv.NewJSONWriter(&b, csv.JSONOptions{NDJSON: tt.ndjson, Types: transformation.Types})
+			reader, _ := csv.NewReader(strings.NewReader(tt.input), csv.Dialect{})
+			if _, err := transformation.Execute(reader, writer, true, -1); err != nil {
+				t.Fatalf("execute error = %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("close error = %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}